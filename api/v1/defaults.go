@@ -0,0 +1,70 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+// Defaults are the values of the fields of the spec of an HNews
+// which aren't set. They are set by the defaulting webhook.
+//+kubebuilder:object:generate=false
+type Defaults struct {
+	Type        Type
+	Limit       int
+	Score       Comparison
+	Descendants Comparison
+}
+
+// BuiltinDefaults are the defaults unless the controller is configured otherwise
+var BuiltinDefaults = Defaults{
+	Type:        Story,
+	Limit:       5,
+	Score:       ">200",
+	Descendants: ">5",
+}
+
+// Apply sets the fields of spec which aren't set to the defaults.
+// Defaults which aren't set are taken from BuiltinDefaults.
+func (d Defaults) Apply(spec *HNewsSpec) {
+	d = d.orBuiltin()
+	if spec.Filter.Type == "" {
+		spec.Filter.Type = string(d.Type)
+	}
+	if spec.Filter.Limit == 0 {
+		spec.Filter.Limit = d.Limit
+	}
+	if spec.Filter.Score == "" {
+		spec.Filter.Score = d.Score
+	}
+	if spec.Filter.Descendants == "" {
+		spec.Filter.Descendants = d.Descendants
+	}
+}
+
+// orBuiltin returns d with the defaults which aren't set taken from BuiltinDefaults
+func (d Defaults) orBuiltin() Defaults {
+	if d.Type == "" {
+		d.Type = BuiltinDefaults.Type
+	}
+	if d.Limit == 0 {
+		d.Limit = BuiltinDefaults.Limit
+	}
+	if d.Score == "" {
+		d.Score = BuiltinDefaults.Score
+	}
+	if d.Descendants == "" {
+		d.Descendants = BuiltinDefaults.Descendants
+	}
+	return d
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+// AllFeeds are all the feeds, in the order of the enum of Feed
+var AllFeeds = []Feed{TopFeed, NewFeed, BestFeed, AskFeed, ShowFeed, JobFeed}
+
+// feedTypes are the types of the items each feed can contain.
+// Ask HN, Show HN and Launch HN posts are stories,
+// polls show up in the general lists like stories do
+// and job postings are in the job list as well as the general lists.
+// Comments and poll options are never in a feed, but comments
+// are in the discussion threads of its stories and polls.
+var feedTypes = map[Feed][]Type{
+	TopFeed:  {Story, Poll, Job},
+	NewFeed:  {Story, Poll, Job},
+	BestFeed: {Story, Poll, Job},
+	AskFeed:  {Story, Poll},
+	ShowFeed: {Story},
+	JobFeed:  {Job},
+}
+
+// CanContain tells if the feed can have items of type t,
+// or comments in the threads of its items for Comment
+func (f Feed) CanContain(t Type) bool {
+	if t == Comment {
+		return f.CanContain(Story) || f.CanContain(Poll)
+	}
+	for _, feedType := range feedTypes[f] {
+		if feedType == t {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultFeedFor returns the feed to look for items of type t in
+// when the spec doesn't select one. It returns "" for the types
+// which are never in a feed.
+func DefaultFeedFor(t Type) Feed {
+	switch t {
+	case Story, Poll, Comment:
+		return TopFeed
+	case Job:
+		return JobFeed
+	}
+	return ""
+}
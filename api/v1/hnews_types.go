@@ -33,6 +33,21 @@ const (
 	PollOpt Type = "pollopt"
 )
 
+// Feed is a list of Hacker News articles
+// Based on the lists specified in https://github.com/HackerNews/API#new-top-and-best-stories
+// and https://github.com/HackerNews/API#ask-show-and-job-stories
+// +kubebuilder:validation:Enum:=top;new;best;ask;show;job
+type Feed string
+
+const (
+	TopFeed  Feed = "top"
+	NewFeed  Feed = "new"
+	BestFeed Feed = "best"
+	AskFeed  Feed = "ask"
+	ShowFeed Feed = "show"
+	JobFeed  Feed = "job"
+)
+
 // Generated using https://mholt.github.io/json-to-go/
 // by converting get Id http json response to go struct
 type GetIdResponse struct {
@@ -45,33 +60,320 @@ type GetIdResponse struct {
 	Title       string `json:"title"`
 	Type        Type   `json:"type"`
 	URL         string `json:"url"`
+	// Text is the HTML text of a comment, story or poll
+	Text string `json:"text,omitempty"`
+	// Parent is the id of the item a comment is a reply to
+	Parent  int  `json:"parent,omitempty"`
+	Deleted bool `json:"deleted,omitempty"`
+	Dead    bool `json:"dead,omitempty"`
+}
+
+// Generated using https://mholt.github.io/json-to-go/
+// by converting get user http json response to go struct
+type GetUserResponse struct {
+	About     string `json:"about"`
+	Created   int    `json:"created"`
+	ID        string `json:"id"`
+	Karma     int    `json:"karma"`
+	Submitted []int  `json:"submitted"`
+}
+
+// Generated using https://mholt.github.io/json-to-go/
+// by converting get updates http json response to go struct
+type GetUpdatesResponse struct {
+	Items    []int    `json:"items"`
+	Profiles []string `json:"profiles"`
 }
 
 // Filter allows you to filter and get the
 // Hacker News articles you want
 type Filter struct {
 	// Number of Hacker News articles you want.
-	// +kubebuilder:validation:Maximum:=20
+	// Only the first 20 (fewer when they are big e.g., with comments)
+	// are in status.link when there are more and all of them
+	// are in HNewsResult pages.
+	// +kubebuilder:validation:Maximum:=500
 	Limit int `json:"limit"`
 	// Type of Hacker News articles you are looking for.
 	// Has to be either of: job,story,comment,poll,pollopt
+	// Comments are looked for in the discussion threads of the stories
+	// and polls in the feeds (see threads) and score, descendents,
+	// title, domains and velocity don't apply to them.
 	// +kubebuilder:validation:Enum:=job;story;comment;poll;pollopt
 	Type string `json:"type,omitempty"`
 	// Score of Hacker News articles you are looking for.
 	// Specify it like:
-	// score: ">=10", score: "<10", score: "=10", score: "!=10"
+	// score: ">=10", score: "<10", score: "=10", score: "!=10",
+	// score: ">=10,<100", score: "between 10 and 100"
 	Score Comparison `json:"score"`
 	// Number of direct (first level) comments in the article.
 	// Specify it like:
-	// descendents: ">=10", descendents: "<10", descendents: "=10", descendents: "!=10"
+	// descendents: ">=10", descendents: "<10", descendents: "=10", descendents: "!=10",
+	// descendents: ">=10,<100", descendents: "between 10 and 100"
 	Descendants Comparison `json:"descendents"`
+	// CEL expression which Hacker News articles have to match
+	// on top of the rest of the filter. The article is `item`
+	// with the fields id, score, descendants, title, url, by,
+	// time (a timestamp), type and kids. Specify it like:
+	// expression: "item.score > 100 || item.descendants > 300"
+	// +optional
+	Expression string `json:"expression,omitempty"`
+	// Title filters Hacker News articles by their title
+	// +optional
+	Title *TitleFilter `json:"title,omitempty"`
+	// Domains filters Hacker News articles by the host of their URL
+	// +optional
+	Domains *DomainFilter `json:"domains,omitempty"`
+	// Author filters Hacker News articles by their author
+	// +optional
+	Author *AuthorFilter `json:"author,omitempty"`
+	// Text filters Hacker News items by their text e.g., comments and Ask HN posts
+	// +optional
+	Text *TextFilter `json:"text,omitempty"`
+	// Number of points per hour Hacker News articles are getting
+	// (see velocity in status.link). Specify it like:
+	// velocity: ">50", velocity: "between 10 and 100"
+	// +optional
+	Velocity Comparison `json:"velocity,omitempty"`
+	// How long ago Hacker News articles can have been posted at most
+	// e.g., maxAge: "12h" for the articles posted in the last 12 hours
+	// +optional
+	MaxAge *metav1.Duration `json:"maxAge,omitempty"`
+	// How long ago Hacker News articles have to have been posted at least
+	// e.g., minAge: "1h" to give the score of new articles time to settle
+	// +optional
+	MinAge *metav1.Duration `json:"minAge,omitempty"`
+	// Hacker News articles have to have been posted at or after it
+	// e.g., since: "2022-05-26T08:00:00Z"
+	// +optional
+	Since *metav1.Time `json:"since,omitempty"`
+	// Hacker News articles have to have been posted before it
+	// e.g., until: "2022-05-26T18:00:00Z"
+	// +optional
+	Until *metav1.Time `json:"until,omitempty"`
+}
+
+// TitleFilter filters Hacker News articles by keywords and regular expressions in their title.
+// The article has to match at least one of include and includeRegex (if any are set)
+// and none of exclude and excludeRegex.
+type TitleFilter struct {
+	// Keywords looked for in the title. They are case insensitive
+	// and have to be whole words e.g., "go" matches "Go 1.18 is released"
+	// but not "Google". Specify them like:
+	// include: ["kubernetes", "go", "postgres"]
+	// +optional
+	Include []string `json:"include,omitempty"`
+	// Keywords which can't be in the title, like include
+	// +optional
+	Exclude []string `json:"exclude,omitempty"`
+	// RE2 regular expressions (https://github.com/google/re2/wiki/Syntax)
+	// looked for in the title. Specify them like:
+	// includeRegex: ["(?i)^show hn:", "\\bv[0-9]+\\.[0-9]+\\b"]
+	// +optional
+	IncludeRegex []string `json:"includeRegex,omitempty"`
+	// RE2 regular expressions which can't match the title, like includeRegex
+	// +optional
+	ExcludeRegex []string `json:"excludeRegex,omitempty"`
+}
+
+// TextFilter filters Hacker News items by keywords, regular expressions and
+// the length of their text without HTML. Items without a text (e.g., most stories)
+// have an empty one. The keywords and regular expressions work like the ones of TitleFilter.
+type TextFilter struct {
+	// Keywords looked for in the text. Specify them like:
+	// include: ["kubernetes", "go", "postgres"]
+	// +optional
+	Include []string `json:"include,omitempty"`
+	// Keywords which can't be in the text, like include
+	// +optional
+	Exclude []string `json:"exclude,omitempty"`
+	// RE2 regular expressions looked for in the text
+	// +optional
+	IncludeRegex []string `json:"includeRegex,omitempty"`
+	// RE2 regular expressions which can't match the text, like includeRegex
+	// +optional
+	ExcludeRegex []string `json:"excludeRegex,omitempty"`
+	// Number of characters the text has to have at least
+	// +kubebuilder:validation:Minimum:=0
+	// +optional
+	MinLength int `json:"minLength,omitempty"`
+	// Number of characters the text can have at most
+	// +kubebuilder:validation:Minimum:=0
+	// +optional
+	MaxLength int `json:"maxLength,omitempty"`
 }
 
+// Comparison is a comma separated list of comparisons with a number
+// which all have to match e.g., ">=10,<100". See pkg/comparison.
 type Comparison string
 
+// DomainFilter filters Hacker News articles by the host of their URL.
+// A domain like "github.com" matches it and its subdomains e.g., "gist.github.com"
+// and a domain like "*.substack.com" only matches its subdomains.
+type DomainFilter struct {
+	// Domains the URL has to be on, if any are set. Specify them like:
+	// include: ["github.com", "*.substack.com"]
+	// +optional
+	Include []string `json:"include,omitempty"`
+	// Domains the URL can't be on e.g., paywalled sites
+	// +optional
+	Exclude []string `json:"exclude,omitempty"`
+	// SelfPosts decides what happens to the articles without a URL
+	// (e.g., Ask HN) which include and exclude can't apply to.
+	// Has to be either of: include,exclude,only
+	// Defaults to include.
+	// +kubebuilder:validation:Enum:=include;exclude;only
+	// +optional
+	SelfPosts SelfPosts `json:"selfPosts,omitempty"`
+}
+
+// SelfPosts decides what happens to the Hacker News articles without a URL
+type SelfPosts string
+
+const (
+	// IncludeSelfPosts includes them along with the articles on the domains
+	IncludeSelfPosts SelfPosts = "include"
+	// ExcludeSelfPosts leaves them out
+	ExcludeSelfPosts SelfPosts = "exclude"
+	// OnlySelfPosts leaves out everything else
+	OnlySelfPosts SelfPosts = "only"
+)
+
+// AuthorFilter filters Hacker News articles by their author.
+// Karma and minAccountAge need the profile of the author
+// which is fetched from the API (and cached) only when they are set.
+type AuthorFilter struct {
+	// Usernames (case-sensitive, like on Hacker News) the author has to be one of,
+	// if any are set. Specify them like: include: ["dang", "pg"]
+	// +optional
+	Include []string `json:"include,omitempty"`
+	// Usernames the author can't be one of
+	// +optional
+	Exclude []string `json:"exclude,omitempty"`
+	// Karma of the author. Specify it like: karma: ">=1000"
+	// +optional
+	Karma Comparison `json:"karma,omitempty"`
+	// How old the account of the author has to be at least
+	// e.g., minAccountAge: "720h" leaves out the articles
+	// posted by accounts created in the last 30 days
+	// +optional
+	MinAccountAge *metav1.Duration `json:"minAccountAge,omitempty"`
+}
+
+// SortOrder is the order of the Hacker News articles in the status of an HNews
+type SortOrder string
+
+const (
+	// SortByRank is the order of the articles in the feeds
+	SortByRank SortOrder = "rank"
+	// SortByScore puts the articles with the highest score first
+	SortByScore SortOrder = "score"
+	// SortByDescendants puts the articles with the most comments first
+	SortByDescendants SortOrder = "descendants"
+	// SortByNewest puts the most recently posted articles first
+	SortByNewest SortOrder = "newest"
+	// SortByOldest puts the least recently posted articles first
+	SortByOldest SortOrder = "oldest"
+	// SortByHot puts the articles with the highest score
+	// for their age first, like the Hacker News front page
+	SortByHot SortOrder = "hot"
+)
+
+// Mode decides if the new Hacker News articles of an HNews are reported
+type Mode string
+
+const (
+	// ModeAll only reports all the articles of the last sync in status.link
+	ModeAll Mode = "all"
+	// ModeNewOnly also reports the articles which weren't
+	// in any of the previous syncs in status.newLinks
+	ModeNewOnly Mode = "newOnly"
+)
+
+// CommentsSpec decides which comments of a Hacker News article are added to its link
+type CommentsSpec struct {
+	// Number of comments at every level i.e., the first count
+	// top level comments and the first count replies to each of them.
+	// Deleted and dead comments are skipped. At most 20 comments
+	// (and 16KiB of their text) are added to a link.
+	// +kubebuilder:validation:Minimum:=1
+	// +kubebuilder:validation:Maximum:=5
+	Count int `json:"count"`
+	// Number of levels of comments e.g., 1 for only the top level
+	// comments and 2 for them and their replies. Defaults to 1.
+	// +kubebuilder:validation:Minimum:=1
+	// +kubebuilder:validation:Maximum:=2
+	// +optional
+	Depth int `json:"depth,omitempty"`
+}
+
+// ThreadsSpec bounds the walk of the discussion threads of the stories
+// in the feeds when an HNews looks for comments. The stories are walked in
+// the order of the feeds, with the replies to a comment right after it,
+// until filter.limit comments match or one of the bounds is reached.
+type ThreadsSpec struct {
+	// Number of levels of comments walked e.g., 1 for only the top level
+	// comments and 2 for them and their replies. Defaults to 3.
+	// +kubebuilder:validation:Minimum:=1
+	// +kubebuilder:validation:Maximum:=10
+	// +optional
+	Depth int `json:"depth,omitempty"`
+	// Number of items (stories and comments) fetched from the API
+	// at most in a sync. Defaults to 1000.
+	// +kubebuilder:validation:Minimum:=1
+	// +kubebuilder:validation:Maximum:=10000
+	// +optional
+	MaxItems int `json:"maxItems,omitempty"`
+	// How long the walk can take at most in a sync e.g., timeout: "1m"
+	// The comments found until then are kept. Defaults to 30s.
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
 // HNewsSpec defines the desired state of HNews
 type HNewsSpec struct {
+	// Feed to get the Hacker News articles from.
+	// Has to be either of: top,new,best,ask,show,job
+	// Defaults to the feed for the type in the filter
+	// (job for job, top otherwise) if neither feed nor feeds is set.
+	// +optional
+	Feed Feed `json:"feed,omitempty"`
+	// Feeds to get the Hacker News articles from along with feed.
+	// The feeds are merged in order and an article
+	// which is in more than one of them is only looked at once.
+	// +optional
+	Feeds  []Feed `json:"feeds,omitempty"`
 	Filter Filter `json:"filter,omitempty"`
+	// Sort is the order of the Hacker News articles in the status.
+	// Has to be either of: rank,score,descendants,newest,oldest,hot
+	// All the articles which match the filter are sorted and then
+	// the first filter.limit of them are kept. Defaults to rank,
+	// the order of the articles in the feeds. Comments don't have
+	// a score and are always in the order of their threads (rank).
+	// +kubebuilder:validation:Enum:=rank;score;descendants;newest;oldest;hot
+	// +optional
+	Sort SortOrder `json:"sort,omitempty"`
+	// Mode decides if the Hacker News articles which are new since
+	// the previous syncs are reported in status.newLinks.
+	// Has to be either of: all,newOnly
+	// Defaults to all.
+	// +kubebuilder:validation:Enum:=all;newOnly
+	// +optional
+	Mode Mode `json:"mode,omitempty"`
+	// Comments are the comments of the Hacker News articles
+	// which are added to their links
+	// +optional
+	Comments *CommentsSpec `json:"comments,omitempty"`
+	// Threads bounds the walk of the discussion threads
+	// when filter.type is comment
+	// +optional
+	Threads *ThreadsSpec `json:"threads,omitempty"`
+	// How often the Hacker News articles are synced
+	// e.g., syncInterval: "10m", syncInterval: "1h30m"
+	// The controller's default is used if it's not set
+	// and its minimum is used if it's lower than that.
+	// +optional
+	SyncInterval *metav1.Duration `json:"syncInterval,omitempty"`
 }
 
 // HNewsStatus defines the observed state of HNews
@@ -79,11 +381,129 @@ type HNewsStatus struct {
 	// Important: Run "make" to regenerate code after modifying this file
 	Links        []Link      `json:"link"`
 	LastSyncedAt metav1.Time `json:"lastSyncedAt,omitempty"`
+	// NextSyncAt is when the Hacker News articles will be synced next,
+	// or retried when the last sync failed because of the API.
+	// It isn't set while the spec is invalid.
+	NextSyncAt metav1.Time `json:"nextSyncAt,omitempty"`
+	// Conditions represent the latest available observations of the HNews' state
+	// +optional
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+	// ObservedGeneration is the generation of the spec the conditions are about
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Results refers to the HNewsResult pages with all the links
+	// when there are more of them than fit in link
+	// +optional
+	Results *ResultsRef `json:"results,omitempty"`
+	// NewLinks are the links of the last sync which weren't in any
+	// of the previous syncs, in newOnly mode. Only the first 20 (fewer
+	// when they are big) are here when there are more, and all of them are marked new in link
+	// and in the HNewsResult pages.
+	// +optional
+	NewLinks []Link `json:"newLinks,omitempty"`
+	// SeenIDs are the ids of the Hacker News articles which were
+	// in the last syncs, in newOnly mode. The ones which weren't in a sync
+	// for the longest are forgotten when there are more than 1000.
+	// +optional
+	SeenIDs []int `json:"seenIDs,omitempty"`
+}
+
+// MaxInlineLinks is the number of links kept in the status of an HNews.
+// Fewer are kept when they are big e.g., with comments.
+// All the links are in HNewsResult pages when there are more.
+const MaxInlineLinks = 20
+
+// MaxSeenIDs is the number of ids of Hacker News articles
+// an HNews remembers in newOnly mode
+const MaxSeenIDs = 1000
+
+// ResultsRef refers to the HNewsResult pages of an HNews
+type ResultsRef struct {
+	// Count is the number of links in all the pages
+	Count int `json:"count"`
+	// Pages are the names of the HNewsResult pages, in order
+	Pages []string `json:"pages"`
 }
 
+const (
+	// ConditionReady is True when the links in the status
+	// are from a successful sync of the current spec
+	ConditionReady = "Ready"
+	// ConditionSynced is True when the last sync succeeded
+	// and False while the current spec is being synced for the first time
+	ConditionSynced = "Synced"
+	// ConditionInvalidSpec is True when the spec can't be honored
+	// e.g., when none of the feeds can contain the type in the filter
+	ConditionInvalidSpec = "InvalidSpec"
+	// ConditionDegraded is True when the last sync couldn't get
+	// (all of) the Hacker News articles from the API
+	ConditionDegraded = "Degraded"
+)
+
+const (
+	// ReasonSynced is used when the last sync succeeded
+	ReasonSynced = "Synced"
+	// ReasonSyncing is used while the current spec is being synced for the first time
+	ReasonSyncing = "Syncing"
+	// ReasonValid is used when the spec can be honored
+	ReasonValid = "Valid"
+	// ReasonInvalidSpec is used when the spec can't be honored
+	// and there is no more specific reason
+	ReasonInvalidSpec = "InvalidSpec"
+	// ReasonInvalidComparison is used when
+	// a comparison in the filter can't be parsed
+	ReasonInvalidComparison = "InvalidComparison"
+	// ReasonFeedTypeMismatch is used when a feed can't contain
+	// the type of the Hacker News articles in the filter
+	ReasonFeedTypeMismatch = "FeedTypeMismatch"
+	// ReasonInvalidTitleFilter is used when a keyword or
+	// a regular expression in the title filter is invalid
+	ReasonInvalidTitleFilter = "InvalidTitleFilter"
+	// ReasonInvalidDomainFilter is used when
+	// a domain in the domain filter is invalid
+	ReasonInvalidDomainFilter = "InvalidDomainFilter"
+	// ReasonInvalidAuthorFilter is used when
+	// a username or the karma in the author filter is invalid
+	ReasonInvalidAuthorFilter = "InvalidAuthorFilter"
+	// ReasonInvalidTextFilter is used when a keyword, a regular
+	// expression or the lengths in the text filter are invalid
+	ReasonInvalidTextFilter = "InvalidTextFilter"
+	// ReasonInvalidTimeWindow is used when maxAge, minAge,
+	// since and until in the filter contradict each other
+	ReasonInvalidTimeWindow = "InvalidTimeWindow"
+	// ReasonExpressionCompileError is used when
+	// the expression in the filter doesn't compile
+	ReasonExpressionCompileError = "ExpressionCompileError"
+	// ReasonAPIHealthy is used when everything was fetched from the API
+	ReasonAPIHealthy = "APIHealthy"
+	// ReasonAPIError is used when the API failed
+	ReasonAPIError = "APIError"
+	// ReasonCircuitOpen is used when the API wasn't called
+	// because it has been failing
+	ReasonCircuitOpen = "CircuitOpen"
+	// ReasonStatusUpdateError is used when the status
+	// couldn't be updated at the start of a sync
+	ReasonStatusUpdateError = "StatusUpdateError"
+	// ReasonResultsError is used when the HNewsResult pages
+	// couldn't be read or written
+	ReasonResultsError = "ResultsError"
+	// ReasonItemsUnavailable is used when some of the
+	// Hacker News articles couldn't be fetched from the API
+	ReasonItemsUnavailable = "ItemsUnavailable"
+	// ReasonCommentsUnavailable is used when the comments of some of the
+	// Hacker News articles couldn't be fetched from the API
+	ReasonCommentsUnavailable = "CommentsUnavailable"
+)
+
 // Link holds the information about
 // Hacker News article for which satisfies the filter
 type Link struct {
+	// ID is the id of the Hacker News article
+	ID int `json:"id,omitempty"`
 	// HNewsUrl refers to the URL of the HNews page
 	// e.g., https://news.ycombinator.com/item?id=31316372
 	HNewsUrl string `json:"hnews_url"`
@@ -92,15 +512,83 @@ type Link struct {
 	ArticleUrl  string `json:"article_url"`
 	Descendents int    `json:"descendents"`
 	Score       int    `json:"score"`
+	// Title is the title of the Hacker News article
+	Title string `json:"title,omitempty"`
+	// Rank is the (1 based) position of the Hacker News article in the feeds,
+	// or of the comment among the ones found in the threads
+	Rank int `json:"rank,omitempty"`
+	// New is true when the Hacker News article wasn't in any of
+	// the previous syncs, in newOnly mode
+	New bool `json:"new,omitempty"`
+	// FirstSeenAt is when the Hacker News article was first synced
+	FirstSeenAt *metav1.Time `json:"firstSeenAt,omitempty"`
+	// PeakScore is the highest score the Hacker News article had in a sync
+	PeakScore int `json:"peakScore,omitempty"`
+	// Velocity is how fast the Hacker News article is getting points and comments
+	Velocity *Velocity `json:"velocity,omitempty"`
+	// Comments are the comments picked by spec.comments,
+	// with the replies to a comment right after it
+	Comments []LinkComment `json:"comments,omitempty"`
+	// Comment is the comment when filter.type is comment.
+	// The link is then the one of the comment.
+	Comment *LinkComment `json:"comment,omitempty"`
+	// Story is the story (or poll) the comment is in
+	// when filter.type is comment
+	Story *LinkStory `json:"story,omitempty"`
+}
+
+// LinkStory is the story a comment is in
+type LinkStory struct {
+	// ID is the id of the story
+	ID int `json:"id"`
+	// Title is the title of the story
+	Title string `json:"title"`
+	// HNewsUrl refers to the URL of the HNews page of the story
+	HNewsUrl string `json:"hnews_url"`
+	// ArticleUrl refers to the URL which is shared in the story
+	ArticleUrl string `json:"article_url,omitempty"`
+}
+
+// LinkComment is a comment of a Hacker News article
+type LinkComment struct {
+	// ID is the id of the comment
+	ID int `json:"id"`
+	// Parent is the id of the comment (or the article) it is a reply to
+	Parent int `json:"parent"`
+	// Depth is 1 for the top level comments, 2 for the replies to them and so on
+	Depth int `json:"depth"`
+	// By is the username of the author
+	By string `json:"by"`
+	// Text is the text of the comment without HTML
+	Text string `json:"text"`
+}
+
+// Velocity is how fast a Hacker News article is getting points and comments.
+// It's measured from an earlier observation of the article (from when it was
+// posted when it's first synced) which is moved forward every hour or so.
+type Velocity struct {
+	// Points is the number of points the article got per hour
+	Points int `json:"points"`
+	// Comments is the number of comments the article got per hour
+	Comments int `json:"comments"`
+	// Since is when the observation the velocity is measured from was made
+	Since metav1.Time `json:"since"`
+	// SinceScore is the score of the article at since
+	SinceScore int `json:"sinceScore"`
+	// SinceDescendents is the number of comments of the article at since
+	SinceDescendents int `json:"sinceDescendents"`
 }
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:JSONPath=.status.conditions[?(@.type=="Ready")].status,name=Ready,type=string
+//+kubebuilder:printcolumn:JSONPath=.status.conditions[?(@.type=="Ready")].reason,name=Reason,type=string,priority=1
 //+kubebuilder:printcolumn:JSONPath=.spec.filter.type,name=Type,type=string
 //+kubebuilder:printcolumn:JSONPath=.spec.filter.score,name=Score,type=string
 //+kubebuilder:printcolumn:JSONPath=.spec.filter.limit,name=Limit,type=integer
 //+kubebuilder:printcolumn:JSONPath=.spec.filter.descendents,name=Descendents,type=string
 //+kubebuilder:printcolumn:JSONPath=.status.lastSyncedAt,name=LastSyncedAt,type=string
+//+kubebuilder:printcolumn:JSONPath=.status.nextSyncAt,name=NextSyncAt,type=string,priority=1
 // HNews is the Schema for the hnews API
 type HNews struct {
 	metav1.TypeMeta   `json:",inline"`
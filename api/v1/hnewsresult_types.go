@@ -0,0 +1,57 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// HNewsUIDLabel is the label with the UID of the HNews an HNewsResult is a page of.
+// Label values can't be as long as names, so it isn't the name.
+const HNewsUIDLabel = "apps.vadasambar.com/hnews-uid"
+
+//+kubebuilder:object:root=true
+//+kubebuilder:printcolumn:JSONPath=.hnews,name=HNews,type=string
+//+kubebuilder:printcolumn:JSONPath=.page,name=Page,type=integer
+//+kubebuilder:printcolumn:JSONPath=.metadata.creationTimestamp,name=Age,type=date
+// HNewsResult is a page of the links of an HNews which has more of them
+// than fit in its status. The pages are created, updated and deleted
+// by the controller and are owned by the HNews.
+type HNewsResult struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// HNews is the name of the HNews the page is of
+	HNews string `json:"hnews"`
+	// Page is the (0 based) index of the page
+	Page int `json:"page"`
+	// Links are the Hacker News articles in the page, in order
+	Links []Link `json:"links"`
+}
+
+//+kubebuilder:object:root=true
+
+// HNewsResultList contains a list of HNewsResult
+type HNewsResultList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []HNewsResult `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&HNewsResult{}, &HNewsResultList{})
+}
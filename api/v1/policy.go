@@ -0,0 +1,28 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+// Annotations on a namespace which restrict the HNews in it.
+// They are enforced by the validating webhook.
+const (
+	// MaxLimitAnnotation is the highest `spec.filter.limit` allowed
+	// e.g., apps.vadasambar.com/hnews-max-limit: "10"
+	MaxLimitAnnotation = "apps.vadasambar.com/hnews-max-limit"
+	// AllowedFeedsAnnotation is the comma separated list of the feeds which can be selected
+	// e.g., apps.vadasambar.com/hnews-allowed-feeds: "top,best"
+	AllowedFeedsAnnotation = "apps.vadasambar.com/hnews-allowed-feeds"
+)
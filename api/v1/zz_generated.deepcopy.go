@@ -22,12 +22,121 @@ limitations under the License.
 package v1
 
 import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AuthorFilter) DeepCopyInto(out *AuthorFilter) {
+	*out = *in
+	if in.Include != nil {
+		in, out := &in.Include, &out.Include
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Exclude != nil {
+		in, out := &in.Exclude, &out.Exclude
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MinAccountAge != nil {
+		in, out := &in.MinAccountAge, &out.MinAccountAge
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuthorFilter.
+func (in *AuthorFilter) DeepCopy() *AuthorFilter {
+	if in == nil {
+		return nil
+	}
+	out := new(AuthorFilter)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CommentsSpec) DeepCopyInto(out *CommentsSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CommentsSpec.
+func (in *CommentsSpec) DeepCopy() *CommentsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CommentsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DomainFilter) DeepCopyInto(out *DomainFilter) {
+	*out = *in
+	if in.Include != nil {
+		in, out := &in.Include, &out.Include
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Exclude != nil {
+		in, out := &in.Exclude, &out.Exclude
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DomainFilter.
+func (in *DomainFilter) DeepCopy() *DomainFilter {
+	if in == nil {
+		return nil
+	}
+	out := new(DomainFilter)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Filter) DeepCopyInto(out *Filter) {
 	*out = *in
+	if in.Title != nil {
+		in, out := &in.Title, &out.Title
+		*out = new(TitleFilter)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Domains != nil {
+		in, out := &in.Domains, &out.Domains
+		*out = new(DomainFilter)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Author != nil {
+		in, out := &in.Author, &out.Author
+		*out = new(AuthorFilter)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Text != nil {
+		in, out := &in.Text, &out.Text
+		*out = new(TextFilter)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MaxAge != nil {
+		in, out := &in.MaxAge, &out.MaxAge
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.MinAge != nil {
+		in, out := &in.MinAge, &out.MinAge
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Since != nil {
+		in, out := &in.Since, &out.Since
+		*out = (*in).DeepCopy()
+	}
+	if in.Until != nil {
+		in, out := &in.Until, &out.Until
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Filter.
@@ -60,12 +169,57 @@ func (in *GetIdResponse) DeepCopy() *GetIdResponse {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GetUpdatesResponse) DeepCopyInto(out *GetUpdatesResponse) {
+	*out = *in
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]int, len(*in))
+		copy(*out, *in)
+	}
+	if in.Profiles != nil {
+		in, out := &in.Profiles, &out.Profiles
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GetUpdatesResponse.
+func (in *GetUpdatesResponse) DeepCopy() *GetUpdatesResponse {
+	if in == nil {
+		return nil
+	}
+	out := new(GetUpdatesResponse)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GetUserResponse) DeepCopyInto(out *GetUserResponse) {
+	*out = *in
+	if in.Submitted != nil {
+		in, out := &in.Submitted, &out.Submitted
+		*out = make([]int, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GetUserResponse.
+func (in *GetUserResponse) DeepCopy() *GetUserResponse {
+	if in == nil {
+		return nil
+	}
+	out := new(GetUserResponse)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HNews) DeepCopyInto(out *HNews) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 }
 
@@ -119,10 +273,94 @@ func (in *HNewsList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HNewsResult) DeepCopyInto(out *HNewsResult) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	if in.Links != nil {
+		in, out := &in.Links, &out.Links
+		*out = make([]Link, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HNewsResult.
+func (in *HNewsResult) DeepCopy() *HNewsResult {
+	if in == nil {
+		return nil
+	}
+	out := new(HNewsResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HNewsResult) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HNewsResultList) DeepCopyInto(out *HNewsResultList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]HNewsResult, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HNewsResultList.
+func (in *HNewsResultList) DeepCopy() *HNewsResultList {
+	if in == nil {
+		return nil
+	}
+	out := new(HNewsResultList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HNewsResultList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HNewsSpec) DeepCopyInto(out *HNewsSpec) {
 	*out = *in
-	out.Filter = in.Filter
+	if in.Feeds != nil {
+		in, out := &in.Feeds, &out.Feeds
+		*out = make([]Feed, len(*in))
+		copy(*out, *in)
+	}
+	in.Filter.DeepCopyInto(&out.Filter)
+	if in.Comments != nil {
+		in, out := &in.Comments, &out.Comments
+		*out = new(CommentsSpec)
+		**out = **in
+	}
+	if in.Threads != nil {
+		in, out := &in.Threads, &out.Threads
+		*out = new(ThreadsSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SyncInterval != nil {
+		in, out := &in.SyncInterval, &out.SyncInterval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HNewsSpec.
@@ -141,9 +379,36 @@ func (in *HNewsStatus) DeepCopyInto(out *HNewsStatus) {
 	if in.Links != nil {
 		in, out := &in.Links, &out.Links
 		*out = make([]Link, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	in.LastSyncedAt.DeepCopyInto(&out.LastSyncedAt)
+	in.NextSyncAt.DeepCopyInto(&out.NextSyncAt)
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Results != nil {
+		in, out := &in.Results, &out.Results
+		*out = new(ResultsRef)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NewLinks != nil {
+		in, out := &in.NewLinks, &out.NewLinks
+		*out = make([]Link, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SeenIDs != nil {
+		in, out := &in.SeenIDs, &out.SeenIDs
+		*out = make([]int, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HNewsStatus.
@@ -159,6 +424,30 @@ func (in *HNewsStatus) DeepCopy() *HNewsStatus {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Link) DeepCopyInto(out *Link) {
 	*out = *in
+	if in.FirstSeenAt != nil {
+		in, out := &in.FirstSeenAt, &out.FirstSeenAt
+		*out = (*in).DeepCopy()
+	}
+	if in.Velocity != nil {
+		in, out := &in.Velocity, &out.Velocity
+		*out = new(Velocity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Comments != nil {
+		in, out := &in.Comments, &out.Comments
+		*out = make([]LinkComment, len(*in))
+		copy(*out, *in)
+	}
+	if in.Comment != nil {
+		in, out := &in.Comment, &out.Comment
+		*out = new(LinkComment)
+		**out = **in
+	}
+	if in.Story != nil {
+		in, out := &in.Story, &out.Story
+		*out = new(LinkStory)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Link.
@@ -170,3 +459,159 @@ func (in *Link) DeepCopy() *Link {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LinkComment) DeepCopyInto(out *LinkComment) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LinkComment.
+func (in *LinkComment) DeepCopy() *LinkComment {
+	if in == nil {
+		return nil
+	}
+	out := new(LinkComment)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LinkStory) DeepCopyInto(out *LinkStory) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LinkStory.
+func (in *LinkStory) DeepCopy() *LinkStory {
+	if in == nil {
+		return nil
+	}
+	out := new(LinkStory)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResultsRef) DeepCopyInto(out *ResultsRef) {
+	*out = *in
+	if in.Pages != nil {
+		in, out := &in.Pages, &out.Pages
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResultsRef.
+func (in *ResultsRef) DeepCopy() *ResultsRef {
+	if in == nil {
+		return nil
+	}
+	out := new(ResultsRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TextFilter) DeepCopyInto(out *TextFilter) {
+	*out = *in
+	if in.Include != nil {
+		in, out := &in.Include, &out.Include
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Exclude != nil {
+		in, out := &in.Exclude, &out.Exclude
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.IncludeRegex != nil {
+		in, out := &in.IncludeRegex, &out.IncludeRegex
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExcludeRegex != nil {
+		in, out := &in.ExcludeRegex, &out.ExcludeRegex
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TextFilter.
+func (in *TextFilter) DeepCopy() *TextFilter {
+	if in == nil {
+		return nil
+	}
+	out := new(TextFilter)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ThreadsSpec) DeepCopyInto(out *ThreadsSpec) {
+	*out = *in
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThreadsSpec.
+func (in *ThreadsSpec) DeepCopy() *ThreadsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ThreadsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TitleFilter) DeepCopyInto(out *TitleFilter) {
+	*out = *in
+	if in.Include != nil {
+		in, out := &in.Include, &out.Include
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Exclude != nil {
+		in, out := &in.Exclude, &out.Exclude
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.IncludeRegex != nil {
+		in, out := &in.IncludeRegex, &out.IncludeRegex
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExcludeRegex != nil {
+		in, out := &in.ExcludeRegex, &out.ExcludeRegex
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TitleFilter.
+func (in *TitleFilter) DeepCopy() *TitleFilter {
+	if in == nil {
+		return nil
+	}
+	out := new(TitleFilter)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Velocity) DeepCopyInto(out *Velocity) {
+	*out = *in
+	in.Since.DeepCopyInto(&out.Since)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Velocity.
+func (in *Velocity) DeepCopy() *Velocity {
+	if in == nil {
+		return nil
+	}
+	out := new(Velocity)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	appsv1 "github.com/vadasambar/hnews/api/v1"
+	"github.com/vadasambar/hnews/pkg/hnapi"
+	"github.com/vadasambar/hnews/pkg/htmltext"
+)
+
+// maxCommentLength is the number of characters of a comment kept in a link,
+// so that the status and the result pages don't get too big
+const maxCommentLength = 1000
+
+const (
+	// maxLinkComments is the number of comments added to a link
+	maxLinkComments = 20
+	// maxLinkCommentsBytes is the size of the text of the comments added to a link
+	maxLinkCommentsBytes = 16 * 1024
+)
+
+// commentsBudget is what is left of maxLinkComments and maxLinkCommentsBytes
+// for the comments of a link, along with the number of comments skipped
+type commentsBudget struct {
+	count   int
+	bytes   int
+	skipped int
+}
+
+// comments returns the comments of the item picked by spec, with the replies
+// to a comment right after it, until maxLinkComments or maxLinkCommentsBytes
+// is reached. Comments which can't be fetched are skipped and counted, and an
+// error is only returned when there is no point in going on e.g., when the API is down.
+func (r *HNewsReconciler) comments(ctx context.Context, item *appsv1.GetIdResponse, spec *appsv1.CommentsSpec) ([]appsv1.LinkComment, int, error) {
+	maxDepth := spec.Depth
+	if maxDepth <= 0 {
+		maxDepth = 1
+	}
+	budget := &commentsBudget{count: maxLinkComments, bytes: maxLinkCommentsBytes}
+	comments, err := r.commentsOf(ctx, item.Kids, spec.Count, 1, maxDepth, budget)
+	return comments, budget.skipped, err
+}
+
+func (r *HNewsReconciler) commentsOf(ctx context.Context, kids []int, count, depth, maxDepth int, budget *commentsBudget) ([]appsv1.LinkComment, error) {
+	if count > budget.count {
+		count = budget.count
+	}
+	results, err := hnapi.FetchItems(ctx, r.HNClient, kids, hnapi.FetchOptions{
+		Concurrency: r.FetchConcurrency,
+		Limit:       count,
+		Accept: func(ctx context.Context, item *appsv1.GetIdResponse) (bool, error) {
+			return item.Type == appsv1.Comment && !item.Deleted && !item.Dead, nil
+		},
+		OnError: func(rank int, err error) bool {
+			if errors.Is(err, hnapi.ErrCircuitOpen) || ctx.Err() != nil {
+				return false
+			}
+			log.Log.Error(err, "skipping comment which couldn't be fetched from the API", "id", kids[rank])
+			budget.skipped++
+			return true
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	comments := []appsv1.LinkComment{}
+	for _, result := range results {
+		comment := linkComment(result.Item, depth)
+		if budget.count <= 0 || len(comment.Text) > budget.bytes {
+			break
+		}
+		budget.count--
+		budget.bytes -= len(comment.Text)
+		comments = append(comments, *comment)
+		if depth < maxDepth && len(result.Item.Kids) > 0 && budget.count > 0 {
+			replies, err := r.commentsOf(ctx, result.Item.Kids, count, depth+1, maxDepth, budget)
+			if err != nil {
+				return nil, err
+			}
+			comments = append(comments, replies...)
+		}
+	}
+	return comments, nil
+}
+
+// linkComment returns the comment at depth of its thread for a link
+func linkComment(item *appsv1.GetIdResponse, depth int) *appsv1.LinkComment {
+	return &appsv1.LinkComment{
+		ID:     item.ID,
+		Parent: item.Parent,
+		Depth:  depth,
+		By:     item.By,
+		Text:   htmltext.Truncate(htmltext.Convert(item.Text), maxCommentLength),
+	}
+}
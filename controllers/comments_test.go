@@ -0,0 +1,174 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	testingclock "k8s.io/utils/clock/testing"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appsv1 "github.com/vadasambar/hnews/api/v1"
+	"github.com/vadasambar/hnews/pkg/hnapi"
+)
+
+// newThreadServer serves a story with id 1, in the top feed, and its 5 comments
+// (11 to 15) with 5 replies each (111 to 155), which have a text of textLength
+// characters. The comments with a higher id were posted later.
+func newThreadServer(textLength int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/topstories.json" {
+			_, _ = w.Write([]byte("[1]"))
+			return
+		}
+		var id int
+		if _, err := fmt.Sscanf(r.URL.Path, "/item/%d.json", &id); err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		item := appsv1.GetIdResponse{ID: id, Type: appsv1.Comment, Parent: id / 10, Time: int(testNow.Unix()) - 1000 + id, Text: strings.Repeat("x", textLength)}
+		if id == 1 {
+			item = appsv1.GetIdResponse{ID: 1, Type: appsv1.Story}
+		}
+		if id < 100 {
+			for i := 1; i <= 5; i++ {
+				item.Kids = append(item.Kids, id*10+i)
+			}
+		}
+		_ = json.NewEncoder(w).Encode(item)
+	}))
+}
+
+func TestCommentsBudget(t *testing.T) {
+	tests := []struct {
+		name       string
+		textLength int
+		want       int
+	}{
+		{name: "count", textLength: 10, want: maxLinkComments},
+		{name: "bytes", textLength: maxCommentLength, want: maxLinkCommentsBytes / maxCommentLength},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newThreadServer(tt.textLength)
+			defer server.Close()
+			r := &HNewsReconciler{HNClient: hnapi.New(hnapi.Options{BaseURL: server.URL})}
+
+			story := &appsv1.GetIdResponse{ID: 1, Type: appsv1.Story, Kids: []int{11, 12, 13, 14, 15}}
+			comments, skipped, err := r.comments(context.Background(), story, &appsv1.CommentsSpec{Count: 5, Depth: 2})
+			if err != nil || skipped > 0 {
+				t.Fatalf("got %d comments skipped and error %v; want none", skipped, err)
+			}
+			if len(comments) != tt.want {
+				t.Fatalf("got %d comments; want %d", len(comments), tt.want)
+			}
+			// the replies still come right after their parent
+			if comments[0].ID != 11 || comments[1].ID != 111 || comments[1].Parent != 11 || comments[1].Depth != 2 {
+				t.Errorf("got comments %d, %d; want 11 and its first reply 111", comments[0].ID, comments[1].ID)
+			}
+		})
+	}
+}
+
+func TestReconcileCommentsError(t *testing.T) {
+	hnServer := newFakeHNServer()
+	defer hnServer.Close()
+	// the comments can't be fetched
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, comment := range fakeComments {
+			if r.URL.Path == fmt.Sprintf("/item/%d.json", comment.ID) {
+				http.Error(w, "down", http.StatusInternalServerError)
+				return
+			}
+		}
+		hnServer.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	hn := &appsv1.HNews{
+		ObjectMeta: metav1.ObjectMeta{Name: "comments-error", Namespace: "default", Generation: 1},
+		Spec: appsv1.HNewsSpec{
+			Comments: &appsv1.CommentsSpec{Count: 2},
+			Filter:   appsv1.Filter{Type: string(appsv1.Story), Limit: 3, Score: ">=0", Descendants: ">=0"},
+		},
+	}
+	c := newFakeClient(t, hn)
+	r := &HNewsReconciler{
+		Client: c,
+		HNClient: hnapi.New(hnapi.Options{
+			BaseURL: server.URL,
+			Retry:   hnapi.RetryOptions{MaxRetries: -1},
+			Breaker: hnapi.BreakerOptions{Threshold: 1},
+		}),
+		Clock: testingclock.NewFakePassiveClock(testNow),
+		// the circuit opens after the first comment
+		FetchConcurrency: 1,
+	}
+
+	if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(hn)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Get(context.Background(), client.ObjectKeyFromObject(hn), hn); err != nil {
+		t.Fatal(err)
+	}
+	synced := meta.FindStatusCondition(hn.Status.Conditions, appsv1.ConditionSynced)
+	if synced == nil || synced.Status != metav1.ConditionTrue {
+		t.Errorf("got Synced condition %+v; want True", synced)
+	}
+	if len(hn.Status.Links) != 3 || hn.Status.Links[0].ID != 1 || len(hn.Status.Links[0].Comments) != 0 {
+		t.Errorf("got links %+v; want the stories without their comments", hn.Status.Links)
+	}
+	// the comments of the stories after the first one aren't fetched
+	// once the circuit is open, they are missing all the same
+	degraded := meta.FindStatusCondition(hn.Status.Conditions, appsv1.ConditionDegraded)
+	if degraded == nil || degraded.Status != metav1.ConditionTrue || degraded.Reason != appsv1.ReasonCommentsUnavailable {
+		t.Fatalf("got Degraded condition %+v; want True with reason %s", degraded, appsv1.ReasonCommentsUnavailable)
+	}
+	if want := "the comments of 3 Hacker News articles couldn't be fetched from the API"; degraded.Message != want {
+		t.Errorf("got Degraded message %q; want %q", degraded.Message, want)
+	}
+}
+
+func TestReconcileCommentsOrder(t *testing.T) {
+	server := newThreadServer(10)
+	defer server.Close()
+
+	hn := &appsv1.HNews{
+		ObjectMeta: metav1.ObjectMeta{Name: "comments-order", Namespace: "default", Generation: 1},
+		Spec: appsv1.HNewsSpec{
+			Filter: appsv1.Filter{Type: string(appsv1.Comment), Limit: 5, Score: ">=0", Descendants: ">=0"},
+			// ignored for comments, the newest would be the replies to the last comment
+			Sort: appsv1.SortByNewest,
+		},
+	}
+	c := newFakeClient(t, hn)
+	r := &HNewsReconciler{
+		Client:   c,
+		HNClient: hnapi.New(hnapi.Options{BaseURL: server.URL}),
+		Clock:    testingclock.NewFakePassiveClock(testNow),
+	}
+
+	if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(hn)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Get(context.Background(), client.ObjectKeyFromObject(hn), hn); err != nil {
+		t.Fatal(err)
+	}
+	// the comments are in the order of their thread, with the replies right after their parent
+	want := []int{11, 111, 112, 113, 114}
+	if len(hn.Status.Links) != len(want) {
+		t.Fatalf("got %d links; want %d", len(hn.Status.Links), len(want))
+	}
+	for i, link := range hn.Status.Links {
+		if link.ID != want[i] || link.Rank != i+1 {
+			t.Errorf("got comment %d at rank %d; want %d at rank %d", link.ID, link.Rank, want[i], i+1)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	appsv1 "github.com/vadasambar/hnews/api/v1"
+)
+
+// setSynced sets the Synced condition of hn and the Ready condition which
+// follows from it, which isn't True while the InvalidSpec condition is,
+// and records that the conditions are about the current generation of hn
+func setSynced(hn *appsv1.HNews, status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&hn.Status.Conditions, metav1.Condition{
+		Type:               appsv1.ConditionSynced,
+		Status:             status,
+		ObservedGeneration: hn.Generation,
+		Reason:             reason,
+		Message:            message,
+	})
+
+	ready := metav1.Condition{
+		Type:               appsv1.ConditionReady,
+		Status:             status,
+		ObservedGeneration: hn.Generation,
+		Reason:             reason,
+		Message:            message,
+	}
+	// e.g., the articles were synced but some of the feeds were ignored
+	invalid := meta.FindStatusCondition(hn.Status.Conditions, appsv1.ConditionInvalidSpec)
+	if status == metav1.ConditionTrue && invalid != nil && invalid.Status == metav1.ConditionTrue {
+		ready.Status = metav1.ConditionFalse
+		ready.Reason = invalid.Reason
+		ready.Message = invalid.Message
+	}
+	meta.SetStatusCondition(&hn.Status.Conditions, ready)
+
+	hn.Status.ObservedGeneration = hn.Generation
+}
+
+// setDegraded sets the Degraded condition of hn after a sync in which
+// failed Hacker News articles and the comments of commentsFailed links
+// couldn't be fetched from the API
+func setDegraded(hn *appsv1.HNews, failed, commentsFailed int) {
+	degraded := metav1.Condition{
+		Type:               appsv1.ConditionDegraded,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: hn.Generation,
+		Reason:             appsv1.ReasonAPIHealthy,
+	}
+	messages := []string{}
+	if commentsFailed > 0 {
+		degraded.Status = metav1.ConditionTrue
+		degraded.Reason = appsv1.ReasonCommentsUnavailable
+		messages = append(messages, fmt.Sprintf("the comments of %d Hacker News articles couldn't be fetched from the API", commentsFailed))
+	}
+	if failed > 0 {
+		degraded.Status = metav1.ConditionTrue
+		degraded.Reason = appsv1.ReasonItemsUnavailable
+		messages = append([]string{fmt.Sprintf("%d Hacker News articles couldn't be fetched from the API", failed)}, messages...)
+	}
+	degraded.Message = strings.Join(messages, ", ")
+	meta.SetStatusCondition(&hn.Status.Conditions, degraded)
+}
@@ -0,0 +1,186 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	testingclock "k8s.io/utils/clock/testing"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appsv1 "github.com/vadasambar/hnews/api/v1"
+	"github.com/vadasambar/hnews/pkg/hnapi"
+)
+
+// failingResultsClient fails to list HNewsResult pages
+type failingResultsClient struct {
+	client.Client
+}
+
+func (c *failingResultsClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	if _, ok := list.(*appsv1.HNewsResultList); ok {
+		return errors.New("etcd is on fire")
+	}
+	return c.Client.List(ctx, list, opts...)
+}
+
+func TestReconcileResultsError(t *testing.T) {
+	server := newFakeHNServer()
+	defer server.Close()
+
+	hn := &appsv1.HNews{
+		ObjectMeta: metav1.ObjectMeta{Name: "results-error", Namespace: "default", Generation: 1},
+		Spec:       appsv1.HNewsSpec{Filter: appsv1.Filter{Type: string(appsv1.Story), Limit: 1, Score: ">=0", Descendants: ">=0"}},
+	}
+	hn.Status.Links = []appsv1.Link{{ID: 42}}
+	hn.Status.Results = &appsv1.ResultsRef{Count: 42, Pages: []string{"results-error-0"}}
+	c := &failingResultsClient{Client: newFakeClient(t, hn)}
+	r := &HNewsReconciler{
+		Client:   c,
+		HNClient: hnapi.New(hnapi.Options{BaseURL: server.URL}),
+		Clock:    testingclock.NewFakePassiveClock(testNow),
+	}
+
+	_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(hn)})
+	if err == nil {
+		t.Fatal("got no error; want the error listing the pages")
+	}
+	if err := c.Get(context.Background(), client.ObjectKeyFromObject(hn), hn); err != nil {
+		t.Fatal(err)
+	}
+	synced := meta.FindStatusCondition(hn.Status.Conditions, appsv1.ConditionSynced)
+	if synced == nil || synced.Status != metav1.ConditionFalse || synced.Reason != appsv1.ReasonResultsError {
+		t.Errorf("got Synced condition %+v; want False with reason %s", synced, appsv1.ReasonResultsError)
+	}
+	if len(hn.Status.Links) != 1 || hn.Status.Links[0].ID != 42 {
+		t.Errorf("got links %+v; want the ones of the last sync", hn.Status.Links)
+	}
+}
+
+// storedStatusClient writes only the status of an HNews, and puts
+// the stored HNews back in the object like the API server does
+type storedStatusClient struct {
+	client.Client
+}
+
+func (c *storedStatusClient) Status() client.StatusWriter {
+	return &storedStatusWriter{Client: c.Client}
+}
+
+type storedStatusWriter struct {
+	client.Client
+}
+
+func (w *storedStatusWriter) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	hn, ok := obj.(*appsv1.HNews)
+	if !ok {
+		return w.Client.Status().Update(ctx, obj, opts...)
+	}
+	var stored appsv1.HNews
+	if err := w.Get(ctx, client.ObjectKeyFromObject(hn), &stored); err != nil {
+		return err
+	}
+	stored.Status = hn.Status
+	if err := w.Client.Status().Update(ctx, &stored, opts...); err != nil {
+		return err
+	}
+	stored.DeepCopyInto(hn)
+	return nil
+}
+
+func (w *storedStatusWriter) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	return w.Client.Status().Patch(ctx, obj, patch, opts...)
+}
+
+func TestReconcileDefaultsAfterStatusUpdate(t *testing.T) {
+	server := newFakeHNServer()
+	defer server.Close()
+
+	// the limit isn't set and the defaulting webhook is disabled
+	hn := &appsv1.HNews{
+		ObjectMeta: metav1.ObjectMeta{Name: "defaults", Namespace: "default", Generation: 1},
+		Spec:       appsv1.HNewsSpec{Filter: appsv1.Filter{Type: string(appsv1.Story)}},
+	}
+	c := &storedStatusClient{Client: newFakeClient(t, hn)}
+	r := &HNewsReconciler{
+		Client:   c,
+		HNClient: hnapi.New(hnapi.Options{BaseURL: server.URL}),
+		Clock:    testingclock.NewFakePassiveClock(testNow),
+		Defaults: appsv1.Defaults{Limit: 1, Score: ">=0", Descendants: ">=0"},
+	}
+
+	if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(hn)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Get(context.Background(), client.ObjectKeyFromObject(hn), hn); err != nil {
+		t.Fatal(err)
+	}
+	if hn.Spec.Filter.Limit != 0 {
+		t.Errorf("got limit %d in the stored spec; want the defaults not to be written", hn.Spec.Filter.Limit)
+	}
+	if len(hn.Status.Links) != 1 || hn.Status.Results != nil {
+		t.Errorf("got %d links and results %+v; want the default limit of 1 link", len(hn.Status.Links), hn.Status.Results)
+	}
+}
+
+func TestReconcileNextSyncAt(t *testing.T) {
+	// the API is down
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "down", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	lastSync := metav1.NewTime(testNow.Add(-time.Hour))
+	degraded := &appsv1.HNews{
+		ObjectMeta: metav1.ObjectMeta{Name: "degraded", Namespace: "default", Generation: 1},
+		Spec:       appsv1.HNewsSpec{Filter: appsv1.Filter{Type: string(appsv1.Story), Limit: 1, Score: ">=0", Descendants: ">=0"}},
+	}
+	invalid := &appsv1.HNews{
+		ObjectMeta: metav1.ObjectMeta{Name: "invalid", Namespace: "default", Generation: 1},
+		Spec:       appsv1.HNewsSpec{Filter: appsv1.Filter{Type: string(appsv1.Story), Limit: 1, Score: "lots", Descendants: ">=0"}},
+	}
+	for _, hn := range []*appsv1.HNews{degraded, invalid} {
+		hn.Status.LastSyncedAt = lastSync
+		hn.Status.NextSyncAt = metav1.NewTime(lastSync.Add(5 * time.Minute))
+	}
+	c := newFakeClient(t, degraded, invalid)
+	r := &HNewsReconciler{
+		Client: c,
+		HNClient: hnapi.New(hnapi.Options{
+			BaseURL: server.URL,
+			Retry:   hnapi.RetryOptions{MaxRetries: -1},
+			Breaker: hnapi.BreakerOptions{Threshold: -1},
+		}),
+		Clock: testingclock.NewFakePassiveClock(testNow),
+	}
+
+	// the next sync is the retry
+	result, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(degraded)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Get(context.Background(), client.ObjectKeyFromObject(degraded), degraded); err != nil {
+		t.Fatal(err)
+	}
+	// the status only has seconds
+	if want := metav1.NewTime(testNow.Add(result.RequeueAfter)).Rfc3339Copy(); !degraded.Status.NextSyncAt.Equal(&want) {
+		t.Errorf("got next sync at %v; want %v", degraded.Status.NextSyncAt.Time, want.Time)
+	}
+
+	// there is no next sync until the spec is fixed
+	if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(invalid)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Get(context.Background(), client.ObjectKeyFromObject(invalid), invalid); err != nil {
+		t.Fatal(err)
+	}
+	if !invalid.Status.NextSyncAt.IsZero() {
+		t.Errorf("got next sync at %v; want none", invalid.Status.NextSyncAt.Time)
+	}
+}
@@ -0,0 +1,212 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appsv1 "github.com/vadasambar/hnews/api/v1"
+	"github.com/vadasambar/hnews/pkg/author"
+	"github.com/vadasambar/hnews/pkg/comparison"
+	"github.com/vadasambar/hnews/pkg/domain"
+	"github.com/vadasambar/hnews/pkg/expression"
+	"github.com/vadasambar/hnews/pkg/hnapi"
+	"github.com/vadasambar/hnews/pkg/text"
+	"github.com/vadasambar/hnews/pkg/title"
+	"github.com/vadasambar/hnews/pkg/velocity"
+	"github.com/vadasambar/hnews/pkg/window"
+)
+
+// specError is returned when the spec of an HNews can't be honored
+type specError struct {
+	// reason is the reason of the InvalidSpec condition
+	reason string
+	err    error
+}
+
+func (e *specError) Error() string {
+	return e.err.Error()
+}
+
+func (e *specError) Unwrap() error {
+	return e.err
+}
+
+// itemFilter is the parsed `spec.filter` of an HNews
+type itemFilter struct {
+	itemType    appsv1.Type
+	score       comparison.Comparison
+	descendants comparison.Comparison
+	title       *title.Matcher
+	domains     *domain.Matcher
+	author      *author.Matcher
+	text        *text.Matcher
+	expression  *expression.Expression
+	// velocity is nil if the filter doesn't set it
+	velocity *comparison.Comparison
+	// posted is the range of time the items have to be posted in
+	posted window.Window
+	// now is when the filter was parsed
+	now time.Time
+}
+
+// compiledExpression is `spec.filter.expression` of a generation of an HNews
+type compiledExpression struct {
+	uid        types.UID
+	generation int64
+	expression *expression.Expression
+	err        error
+}
+
+// filterFor parses `spec.filter` of hn.
+// A *specError is returned if it's invalid.
+func (r *HNewsReconciler) filterFor(hn *appsv1.HNews) (*itemFilter, error) {
+	score, err := comparison.Parse(string(hn.Spec.Filter.Score))
+	if err != nil {
+		return nil, &specError{reason: appsv1.ReasonInvalidComparison, err: fmt.Errorf("spec.filter.score: %w", err)}
+	}
+	descendants, err := comparison.Parse(string(hn.Spec.Filter.Descendants))
+	if err != nil {
+		return nil, &specError{reason: appsv1.ReasonInvalidComparison, err: fmt.Errorf("spec.filter.descendents: %w", err)}
+	}
+	var velocity *comparison.Comparison
+	if hn.Spec.Filter.Velocity != "" {
+		v, err := comparison.Parse(string(hn.Spec.Filter.Velocity))
+		if err != nil {
+			return nil, &specError{reason: appsv1.ReasonInvalidComparison, err: fmt.Errorf("spec.filter.velocity: %w", err)}
+		}
+		velocity = &v
+	}
+	titleMatcher, err := title.Compile(hn.Spec.Filter.Title)
+	if err != nil {
+		return nil, &specError{reason: appsv1.ReasonInvalidTitleFilter, err: fmt.Errorf("spec.filter.title.%w", err)}
+	}
+	domains, err := domain.Compile(hn.Spec.Filter.Domains)
+	if err != nil {
+		return nil, &specError{reason: appsv1.ReasonInvalidDomainFilter, err: fmt.Errorf("spec.filter.domains.%w", err)}
+	}
+	authorMatcher, err := author.Compile(hn.Spec.Filter.Author)
+	if err != nil {
+		return nil, &specError{reason: appsv1.ReasonInvalidAuthorFilter, err: fmt.Errorf("spec.filter.author.%w", err)}
+	}
+	textMatcher, err := text.Compile(hn.Spec.Filter.Text)
+	if err != nil {
+		return nil, &specError{reason: appsv1.ReasonInvalidTextFilter, err: fmt.Errorf("spec.filter.text.%w", err)}
+	}
+	now := r.Clock.Now()
+	posted, err := window.New(&hn.Spec.Filter, now)
+	if err != nil {
+		return nil, &specError{reason: appsv1.ReasonInvalidTimeWindow, err: fmt.Errorf("spec.filter.%w", err)}
+	}
+	expr, err := r.expressionFor(hn)
+	if err != nil {
+		return nil, &specError{reason: appsv1.ReasonExpressionCompileError, err: fmt.Errorf("spec.filter.expression: %w", err)}
+	}
+
+	return &itemFilter{
+		itemType:    appsv1.Type(hn.Spec.Filter.Type),
+		score:       score,
+		descendants: descendants,
+		title:       titleMatcher,
+		domains:     domains,
+		author:      authorMatcher,
+		text:        textMatcher,
+		expression:  expr,
+		velocity:    velocity,
+		posted:      posted,
+		now:         now,
+	}, nil
+}
+
+// matches tells if the item is a part of the HNews.
+// Comments don't have a score, a title or a URL, so only
+// the rest of the filter applies to them.
+func (f *itemFilter) matches(item *appsv1.GetIdResponse) (bool, error) {
+	if item.Type != f.itemType || !f.author.Matches(item.By) || !f.text.Matches(item.Text) ||
+		!f.posted.Contains(time.Unix(int64(item.Time), 0)) {
+		return false, nil
+	}
+	if item.Type != appsv1.Comment && (!f.score.Matches(item.Score) || !f.descendants.Matches(item.Descendants) ||
+		!f.title.Matches(item.Title) || !f.domains.Matches(item.URL)) {
+		return false, nil
+	}
+	if f.expression == nil {
+		return true, nil
+	}
+	return f.expression.Matches(item)
+}
+
+// matchesVelocity tells if the item is getting as many points per hour as the filter wants
+func (f *itemFilter) matchesVelocity(tracker *velocity.Tracker, item *appsv1.GetIdResponse) bool {
+	if f.velocity == nil || item.Type == appsv1.Comment {
+		return true
+	}
+	var link appsv1.Link
+	tracker.Track(&link, item)
+	return f.velocity.Matches(link.Velocity.Points)
+}
+
+// matchesProfile tells if the profile of the author of the item matches
+// the author filter. The profile is only fetched if the filter needs it
+// and the item doesn't match if its author doesn't exist anymore.
+func (f *itemFilter) matchesProfile(ctx context.Context, hnClient hnapi.Client, item *appsv1.GetIdResponse) (bool, error) {
+	if !f.author.NeedsProfile() {
+		return true, nil
+	}
+	if item.By == "" {
+		return false, nil
+	}
+	user, err := hnClient.User(ctx, item.By)
+	if errors.Is(err, hnapi.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return f.author.MatchesProfile(user, f.now), nil
+}
+
+// expressionFor returns the compiled `spec.filter.expression` of hn
+// (nil if it isn't set). It's compiled once per generation of hn.
+func (r *HNewsReconciler) expressionFor(hn *appsv1.HNews) (*expression.Expression, error) {
+	key := client.ObjectKeyFromObject(hn)
+	if hn.Spec.Filter.Expression == "" {
+		r.expressions.Delete(key)
+		return nil, nil
+	}
+	if v, ok := r.expressions.Load(key); ok {
+		compiled := v.(*compiledExpression)
+		if compiled.uid == hn.UID && compiled.generation == hn.Generation {
+			return compiled.expression, compiled.err
+		}
+	}
+
+	expr, err := expression.Compile(hn.Spec.Filter.Expression)
+	r.expressions.Store(key, &compiledExpression{
+		uid:        hn.UID,
+		generation: hn.Generation,
+		expression: expr,
+		err:        err,
+	})
+	return expr, err
+}
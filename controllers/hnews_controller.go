@@ -18,20 +18,30 @@ package controllers
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
-	"net/http"
+	"strings"
+	"sync"
 	"time"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/utils/clock"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	appsv1 "github.com/vadasambar/hnews/api/v1"
-	helpers "github.com/vadasambar/hnews/pkg/helpers"
+	"github.com/vadasambar/hnews/pkg/hnapi"
+	"github.com/vadasambar/hnews/pkg/sorting"
+	"github.com/vadasambar/hnews/pkg/velocity"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -39,21 +49,59 @@ import (
 type HNewsReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+	// HNClient is used to talk to the Hacker News API
+	HNClient hnapi.Client
+	// Defaults are used for the fields of the spec which aren't set.
+	// They should be the same as the ones of the defaulting webhook.
+	Defaults appsv1.Defaults
+	// FetchConcurrency is the maximum number of items
+	// fetched from the API at the same time by a single reconcile
+	FetchConcurrency int
+	// DefaultSyncInterval is used for HNews which don't set `spec.syncInterval`
+	DefaultSyncInterval time.Duration
+	// MinSyncInterval is the lowest `spec.syncInterval` that is honored
+	MinSyncInterval time.Duration
+	// UpdatesInterval is how often /updates.json is polled
+	// for the items which changed. It isn't polled if it's 0.
+	UpdatesInterval time.Duration
+	// HNStreamer is used to stream the feeds so that HNews are synced
+	// as soon as their feeds change. The feeds aren't streamed if it's nil.
+	HNStreamer hnapi.Streamer
+	// Clock is used for the time filters and the sync times
+	Clock clock.PassiveClock
+
+	// events triggers syncs of HNews from outside the manager's watches
+	events chan event.GenericEvent
+	// expressions has the compiled `spec.filter.expression` of every HNews
+	// (types.NamespacedName => *compiledExpression)
+	expressions sync.Map
+	// pages has the digest of every HNewsResult page last written
+	// by the controller, or deletedPage if it deleted it
+	// (types.NamespacedName => string)
+	pages sync.Map
 }
 
 const (
-	defaultDescendents = ">5"
-	defaultScore       = ">200"
-	defaultLimit       = 5
-	defaultType        = string(appsv1.Story)
-	topStoriesUrl      = "https://hacker-news.firebaseio.com/v0/topstories.json"
-	getIdRespUrl       = "https://hacker-news.firebaseio.com/v0/item/%s.json"
-	hnewsArticleUrl    = "https://news.ycombinator.com/item?id=%d"
+	hnewsArticleUrl = "https://news.ycombinator.com/item?id=%d"
+	// syncJitterFactor spreads the syncs of HNews with the same
+	// sync interval so that they don't hit the API at the same time
+	syncJitterFactor = 0.1
+	// degradedRetryInterval is how long to wait before syncing again
+	// when the API failed
+	degradedRetryInterval = 30 * time.Second
+)
+
+const (
+	// DefaultSyncInterval is the default for HNewsReconciler.DefaultSyncInterval
+	DefaultSyncInterval = 5 * time.Minute
+	// MinSyncInterval is the default for HNewsReconciler.MinSyncInterval
+	MinSyncInterval = time.Minute
 )
 
 //+kubebuilder:rbac:groups=apps.vadasambar.com,resources=hnews,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=apps.vadasambar.com,resources=hnews/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=apps.vadasambar.com,resources=hnews/finalizers,verbs=update
+//+kubebuilder:rbac:groups=apps.vadasambar.com,resources=hnewsresults,verbs=get;list;watch;create;update;patch;delete
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -66,111 +114,388 @@ const (
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.11.0/pkg/reconcile
 func (r *HNewsReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	_ = log.FromContext(ctx)
+	// API requests for the HNews count against the budget of its namespace
+	ctx = hnapi.WithNamespace(ctx, req.Namespace)
 
 	var hn appsv1.HNews
 	err := r.Client.Get(ctx, req.NamespacedName, &hn)
 	if err != nil {
 		if apierrors.IsNotFound(err) {
 			log.Log.Info("unable to fetch hnews k8s resource", "name", req.Name, "namespace", req.Namespace)
+			r.expressions.Delete(req.NamespacedName)
 			return ctrl.Result{}, nil
 		}
 		log.Log.Error(err, "unable to fetch hnews k8s resource", "name", req.Name, "namespace", req.Namespace)
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
-	if hn.Spec.Filter.Type == "" || hn.Spec.Filter.Limit == 0 || hn.Spec.Filter.Score == "" || hn.Spec.Filter.Descendants == "" {
-		if hn.Spec.Filter.Type == "" {
-			hn.Spec.Filter.Type = defaultType
-		}
-
-		if hn.Spec.Filter.Limit == 0 {
-			hn.Spec.Filter.Limit = defaultLimit
-		}
+	// the defaulting webhook sets the fields which aren't set but it
+	// might be disabled, the defaults are never written to the spec.
+	// The spec is read from a copy from then on, as writing the status
+	// overwrites hn (and its defaults) with the stored HNews.
+	r.Defaults.Apply(&hn.Spec)
+	spec := hn.Spec.DeepCopy()
 
-		if hn.Spec.Filter.Score == "" {
-			hn.Spec.Filter.Score = defaultScore
-		}
+	filter, err := r.filterFor(&hn)
+	if err != nil {
+		return r.invalidSpec(ctx, &hn, err)
+	}
 
-		if hn.Spec.Filter.Descendants == "" {
-			hn.Spec.Filter.Descendants = defaultDescendents
-		}
+	feeds, mismatch := feedsFor(spec)
+	if mismatch != "" {
+		log.Log.Info("ignoring feeds which can't contain the type in the filter", "name", req.Name, "namespace", req.Namespace, "reason", mismatch)
+		meta.SetStatusCondition(&hn.Status.Conditions, metav1.Condition{
+			Type:               appsv1.ConditionInvalidSpec,
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: hn.Generation,
+			Reason:             appsv1.ReasonFeedTypeMismatch,
+			Message:            mismatch,
+		})
+	} else {
+		meta.SetStatusCondition(&hn.Status.Conditions, metav1.Condition{
+			Type:               appsv1.ConditionInvalidSpec,
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: hn.Generation,
+			Reason:             appsv1.ReasonValid,
+		})
+	}
 
-		if err := r.Update(ctx, &hn); err != nil {
-			log.Log.Error(err, "unable to update hnews", "name", req.Name, "namespace", req.Namespace)
-			return ctrl.Result{RequeueAfter: time.Second * 30}, err
+	if hn.Status.ObservedGeneration != hn.Generation {
+		// the links in the status are for the old spec until the sync is done
+		setSynced(&hn, metav1.ConditionFalse, appsv1.ReasonSyncing, fmt.Sprintf("syncing generation %d of the spec", hn.Generation))
+		if err := r.Status().Update(ctx, &hn); err != nil {
+			log.Log.Error(err, "unable to update hnews status", "name", req.Name, "namespace", req.Namespace)
+			return r.failed(ctx, &hn, appsv1.ReasonStatusUpdateError, err)
 		}
-		// reconcile is triggered automatically if the spec is updated
-		// no need to use `Requeue` below`
-		return ctrl.Result{}, nil
 	}
 
-	resp, err := http.Get(topStoriesUrl)
+	ids, err := hnapi.Feeds(ctx, r.HNClient, feeds...)
 	if err != nil {
-		log.Log.Error(err, "error getting response from /topstories.json API")
-		return ctrl.Result{Requeue: true, RequeueAfter: time.Second * 30}, err
+		log.Log.Error(err, "error getting feeds from the API", "feeds", feeds)
+		return r.degraded(ctx, &hn, err)
 	}
 
-	defer resp.Body.Close()
-	body, err := io.ReadAll(resp.Body)
+	previous, err := r.previousLinks(ctx, &hn)
 	if err != nil {
-		log.Log.Error(err, "error reading response from /topstories.json API")
-		return ctrl.Result{}, err
-	}
-	var ids []json.Number
-	err = json.Unmarshal(body, &ids)
-	if err != nil {
-		log.Log.Error(err, "error unmarshalling /topstories.json API response")
-		return ctrl.Result{}, err
+		log.Log.Error(err, "unable to get the links of the last sync", "name", req.Name, "namespace", req.Namespace)
+		return r.failed(ctx, &hn, appsv1.ReasonResultsError, err)
 	}
+	tracker := velocity.NewTracker(previous, hn.Status.LastSyncedAt.Time, filter.now)
 
-	hn.Status.Links = []appsv1.Link{}
-	count := 0
-	for _, id := range ids {
-		if hn.Spec.Filter.Limit == count {
-			break
-		}
-		resp, err := http.Get(fmt.Sprintf(getIdRespUrl, id))
+	// comments don't have a score and are kept in the order of their threads
+	sortOrder := spec.Sort
+	if filter.itemType == appsv1.Comment {
+		sortOrder = appsv1.SortByRank
+	}
+	// fetching can stop at the limit when the articles are kept in the order
+	// of the feeds, but any of them can come first for the other orders
+	fetchLimit := spec.Filter.Limit
+	if sorting.NeedsAll(sortOrder) {
+		fetchLimit = 0
+	}
+	accept := func(ctx context.Context, item *appsv1.GetIdResponse) (bool, error) {
+		matches, err := filter.matches(item)
 		if err != nil {
-			log.Log.Error(err, "error getting response from /item/{item-id}.json API")
-			return ctrl.Result{Requeue: true, RequeueAfter: time.Second * 30}, err
+			log.Log.Error(err, "skipping item the filter couldn't be evaluated for", "id", item.ID, "name", req.Name, "namespace", req.Namespace)
 		}
-
-		defer resp.Body.Close()
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			log.Log.Error(err, "error reading response from /item/{item-id}.json API")
-			return ctrl.Result{}, err
+		if !matches || !filter.matchesVelocity(tracker, item) {
+			return false, nil
 		}
-		var getIdResp appsv1.GetIdResponse
-		err = json.Unmarshal(body, &getIdResp)
-		if err != nil {
-			log.Log.Error(err, "error unmarshalling /item/{item-id}.json API response")
-			return ctrl.Result{}, err
+		// the profile of the author is only fetched for the items
+		// which match everything else
+		return filter.matchesProfile(ctx, r.HNClient, item)
+	}
+	// a few items which can't be fetched shouldn't fail the sync
+	// but there is no point in going on if the API is down
+	failed := 0
+	skip := func(id int, err error) bool {
+		if errors.Is(err, hnapi.ErrCircuitOpen) || ctx.Err() != nil {
+			return false
 		}
+		log.Log.Error(err, "skipping item which couldn't be fetched from the API", "id", id)
+		failed++
+		return true
+	}
+	var (
+		results []hnapi.Result
+		threads map[int]thread
+	)
+	if filter.itemType == appsv1.Comment {
+		// comments aren't in the feeds but in the threads of their stories
+		results, threads, err = r.walkThreads(ctx, ids, spec.Threads, fetchLimit, accept, skip)
+	} else {
+		results, err = hnapi.FetchItems(ctx, r.HNClient, ids, hnapi.FetchOptions{
+			Concurrency: r.FetchConcurrency,
+			Limit:       fetchLimit,
+			Accept:      accept,
+			OnError: func(rank int, err error) bool {
+				return skip(ids[rank], err)
+			},
+		})
+	}
+	if err != nil {
+		log.Log.Error(err, "error getting /item/{item-id}.json from the API")
+		return r.degraded(ctx, &hn, err)
+	}
 
-		if helpers.EvalCond(getIdResp.Score, hn.Spec.Filter.Score) && count < hn.Spec.Filter.Limit && helpers.EvalCond(getIdResp.Descendants, hn.Spec.Filter.Descendants) {
-			hn.Status.Links = append(hn.Status.Links, appsv1.Link{
-				HNewsUrl:    fmt.Sprintf(hnewsArticleUrl, getIdResp.ID),
-				ArticleUrl:  getIdResp.URL,
-				Descendents: getIdResp.Descendants,
-				Score:       getIdResp.Score,
-			})
-			count++
+	sorting.Sort(results, sortOrder, filter.now)
+	if limit := spec.Filter.Limit; limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	links := []appsv1.Link{}
+	// the links are kept without (some of) their comments when they can't
+	// be fetched, and the comments aren't fetched anymore once the API is down
+	commentsFailed, commentsDown := 0, false
+	for _, result := range results {
+		link := appsv1.Link{
+			ID:          result.Item.ID,
+			HNewsUrl:    fmt.Sprintf(hnewsArticleUrl, result.Item.ID),
+			ArticleUrl:  result.Item.URL,
+			Descendents: result.Item.Descendants,
+			Score:       result.Item.Score,
+			Title:       result.Item.Title,
+			Rank:        result.Rank + 1,
+		}
+		if thread, ok := threads[result.Item.ID]; ok {
+			link.Comment = linkComment(result.Item, thread.depth)
+			link.Story = &appsv1.LinkStory{
+				ID:         thread.story.ID,
+				Title:      thread.story.Title,
+				HNewsUrl:   fmt.Sprintf(hnewsArticleUrl, thread.story.ID),
+				ArticleUrl: thread.story.URL,
+			}
+		} else {
+			// comments don't have a score to track
+			tracker.Track(&link, result.Item)
+		}
+		if spec.Comments != nil && commentsDown {
+			commentsFailed++
+		} else if spec.Comments != nil {
+			comments, skipped, err := r.comments(ctx, result.Item, spec.Comments)
+			if err != nil {
+				log.Log.Error(err, "error getting comments from the API", "id", result.Item.ID)
+				commentsDown = errors.Is(err, hnapi.ErrCircuitOpen)
+			}
+			if err != nil || skipped > 0 {
+				commentsFailed++
+			}
+			link.Comments = comments
 		}
+		links = append(links, link)
+	}
+	setDegraded(&hn, failed, commentsFailed)
+	// the links of the last sync are kept if the new ones can't be written
+	lastStatus := hn.Status.DeepCopy()
+	if spec.Mode == appsv1.ModeNewOnly {
+		hn.Status.NewLinks, hn.Status.SeenIDs = markNew(links, hn.Status.SeenIDs)
+	} else {
+		hn.Status.NewLinks, hn.Status.SeenIDs = nil, nil
+	}
+	if err := r.syncResults(ctx, &hn, links); err != nil {
+		log.Log.Error(err, "unable to sync hnews results", "name", req.Name, "namespace", req.Namespace)
+		hn.Status = *lastStatus
+		return r.failed(ctx, &hn, appsv1.ReasonResultsError, err)
 	}
 
-	hn.Status.LastSyncedAt = metav1.NewTime(time.Now())
+	setSynced(&hn, metav1.ConditionTrue, appsv1.ReasonSynced, fmt.Sprintf("synced %d Hacker News articles", len(links)))
+	now := r.Clock.Now()
+	syncInterval := r.syncInterval(spec)
+	hn.Status.LastSyncedAt = metav1.NewTime(now)
+	hn.Status.NextSyncAt = metav1.NewTime(now.Add(syncInterval))
 	if err := r.Status().Update(ctx, &hn); err != nil {
 		log.Log.Error(err, "unable to update hnews status", "name", req.Name, "namespace", req.Namespace)
 		return ctrl.Result{RequeueAfter: time.Second * 30}, err
 	}
 
+	return ctrl.Result{RequeueAfter: syncInterval}, nil
+}
+
+// degraded records in the status that the sync failed because of the API
+// and schedules the next attempt. The links from the last sync are kept.
+func (r *HNewsReconciler) degraded(ctx context.Context, hn *appsv1.HNews, err error) (ctrl.Result, error) {
+	now := r.Clock.Now()
+	reason := appsv1.ReasonAPIError
+	retryIn := degradedRetryInterval
+	var circuitErr *hnapi.CircuitOpenError
+	if errors.As(err, &circuitErr) {
+		reason = appsv1.ReasonCircuitOpen
+		if untilClosed := circuitErr.Until.Sub(now); untilClosed > retryIn {
+			retryIn = untilClosed
+		}
+	}
+	retryIn = wait.Jitter(retryIn, syncJitterFactor)
+
+	meta.SetStatusCondition(&hn.Status.Conditions, metav1.Condition{
+		Type:               appsv1.ConditionDegraded,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: hn.Generation,
+		Reason:             reason,
+		Message:            err.Error(),
+	})
+	setSynced(hn, metav1.ConditionFalse, reason, err.Error())
+	hn.Status.NextSyncAt = metav1.NewTime(now.Add(retryIn))
+	if err := r.Status().Update(ctx, hn); err != nil {
+		log.Log.Error(err, "unable to update hnews status", "name", hn.Name, "namespace", hn.Namespace)
+		return ctrl.Result{RequeueAfter: time.Second * 30}, err
+	}
+
+	return ctrl.Result{RequeueAfter: retryIn}, nil
+}
+
+// failed records in the status that the sync failed for reason when it's
+// neither because of the API nor of the spec e.g., when the HNewsResult pages
+// can't be written, and the sync is retried. The links from the last sync are kept.
+func (r *HNewsReconciler) failed(ctx context.Context, hn *appsv1.HNews, reason string, err error) (ctrl.Result, error) {
+	setSynced(hn, metav1.ConditionFalse, reason, err.Error())
+	// the status might not be writable either, which is what
+	// the sync failed with in the first place
+	if err := r.Status().Update(ctx, hn); err != nil {
+		log.Log.Error(err, "unable to update hnews status", "name", hn.Name, "namespace", hn.Namespace)
+	}
+	return ctrl.Result{RequeueAfter: time.Second * 30}, err
+}
+
+// invalidSpec records in the status that hn isn't synced because of its spec.
+// The next sync is when the spec is fixed.
+func (r *HNewsReconciler) invalidSpec(ctx context.Context, hn *appsv1.HNews, err error) (ctrl.Result, error) {
+	reason := appsv1.ReasonInvalidSpec
+	var specErr *specError
+	if errors.As(err, &specErr) {
+		reason = specErr.reason
+	}
+	log.Log.Info("not syncing hnews because of its spec", "name", hn.Name, "namespace", hn.Namespace, "reason", reason, "error", err.Error())
+
+	meta.SetStatusCondition(&hn.Status.Conditions, metav1.Condition{
+		Type:               appsv1.ConditionInvalidSpec,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: hn.Generation,
+		Reason:             reason,
+		Message:            err.Error(),
+	})
+	setSynced(hn, metav1.ConditionFalse, reason, err.Error())
+	// there is no next sync until the spec changes
+	hn.Status.NextSyncAt = metav1.Time{}
+	if err := r.Status().Update(ctx, hn); err != nil {
+		log.Log.Error(err, "unable to update hnews status", "name", hn.Name, "namespace", hn.Namespace)
+		return ctrl.Result{RequeueAfter: time.Second * 30}, err
+	}
+
 	return ctrl.Result{}, nil
 }
 
+// selectedFeeds returns the feeds selected in the spec without duplicates,
+// or the default feed for the type in the filter if none are
+func selectedFeeds(spec *appsv1.HNewsSpec) []appsv1.Feed {
+	selected := spec.Feeds
+	if spec.Feed != "" {
+		selected = append([]appsv1.Feed{spec.Feed}, selected...)
+	}
+	if len(selected) == 0 {
+		if feed := appsv1.DefaultFeedFor(appsv1.Type(spec.Filter.Type)); feed != "" {
+			selected = []appsv1.Feed{feed}
+		}
+	}
+
+	feeds := []appsv1.Feed{}
+	seen := map[appsv1.Feed]bool{}
+	for _, feed := range selected {
+		if seen[feed] {
+			continue
+		}
+		seen[feed] = true
+		feeds = append(feeds, feed)
+	}
+	return feeds
+}
+
+// feedsFor returns the API feeds which can contain the type in the filter
+// and a message about the selected feeds which can't ("" if there are none)
+func feedsFor(spec *appsv1.HNewsSpec) ([]hnapi.Feed, string) {
+	itemType := appsv1.Type(spec.Filter.Type)
+	selected := selectedFeeds(spec)
+	if len(selected) == 0 {
+		return nil, fmt.Sprintf("items of type %q are not in any feed", itemType)
+	}
+
+	feeds := []hnapi.Feed{}
+	incompatible := []string{}
+	for _, feed := range selected {
+		if !feed.CanContain(itemType) {
+			incompatible = append(incompatible, string(feed))
+			continue
+		}
+		// e.g., show => showstories
+		feeds = append(feeds, hnapi.Feed(feed+"stories"))
+	}
+	if len(incompatible) > 0 {
+		return feeds, fmt.Sprintf("feeds %s can't contain items of type %q", strings.Join(incompatible, ","), itemType)
+	}
+	return feeds, ""
+}
+
+// syncInterval returns how long to wait before syncing an HNews with spec again.
+// It is `spec.syncInterval` (or the controller's default) bounded by
+// the controller's minimum, plus a bit of jitter.
+func (r *HNewsReconciler) syncInterval(spec *appsv1.HNewsSpec) time.Duration {
+	interval := r.DefaultSyncInterval
+	if spec.SyncInterval != nil {
+		interval = spec.SyncInterval.Duration
+	}
+	if interval < r.MinSyncInterval {
+		interval = r.MinSyncInterval
+	}
+	return wait.Jitter(interval, syncJitterFactor)
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *HNewsReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if r.HNClient == nil {
+		r.HNClient = hnapi.New(hnapi.Options{})
+	}
+	if r.DefaultSyncInterval == 0 {
+		r.DefaultSyncInterval = DefaultSyncInterval
+	}
+	if r.MinSyncInterval == 0 {
+		r.MinSyncInterval = MinSyncInterval
+	}
+	if r.Clock == nil {
+		r.Clock = clock.RealClock{}
+	}
+
+	r.events = make(chan event.GenericEvent)
+	if r.UpdatesInterval > 0 {
+		err := mgr.Add(&updatesWatcher{
+			Reader:          mgr.GetClient(),
+			HNClient:        r.HNClient,
+			Interval:        r.UpdatesInterval,
+			MinSyncInterval: r.MinSyncInterval,
+			Clock:           r.Clock,
+			Events:          r.events,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if r.HNStreamer != nil {
+		err := mgr.Add(&streamWatcher{
+			Reader:          mgr.GetClient(),
+			HNClient:        r.HNClient,
+			HNStreamer:      r.HNStreamer,
+			MinSyncInterval: r.MinSyncInterval,
+			Clock:           r.Clock,
+			Events:          r.events,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&appsv1.HNews{}).
+		// status updates don't need a sync, the next one is
+		// scheduled with `RequeueAfter` after every sync
+		For(&appsv1.HNews{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		// the result pages are synced again when they are edited or deleted by hand
+		Owns(&appsv1.HNewsResult{}, builder.WithPredicates(r.pageChangedByOthers())).
+		Watches(&source.Channel{Source: r.events}, &handler.EnqueueRequestForObject{}).
 		Complete(r)
 }
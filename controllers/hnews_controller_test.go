@@ -7,14 +7,16 @@ import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	hnewsv1 "github.com/vadasambar/hnews/api/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 var _ = Describe("HNews Controller", func() {
 	Context("When creating HNews", func() {
 		It("It should create HNews from an empty `spec`", func() {
-			By("By filling in the defaults for empty `spec`")
+			By("By syncing with the defaults without writing them to the `spec`")
 			ctx := context.Background()
 			hnews := &hnewsv1.HNews{
 				TypeMeta: metav1.TypeMeta{
@@ -35,15 +37,697 @@ var _ = Describe("HNews Controller", func() {
 				err := k8sClient.Get(ctx, types.NamespacedName{Name: "hnews-sample", Namespace: "default"}, &hnewsCreated)
 				Expect(err).NotTo(HaveOccurred())
 
-				filter := hnewsCreated.Spec.Filter
-				return filter.Descendants == defaultDescendents &&
-					filter.Limit == defaultLimit &&
-					filter.Score == defaultScore &&
-					filter.Type == defaultType &&
-					len(hnewsCreated.Status.Links) <= defaultLimit
+				return !hnewsCreated.Status.LastSyncedAt.IsZero() &&
+					len(hnewsCreated.Status.Links) <= hnewsv1.BuiltinDefaults.Limit
+			}, time.Second*30, time.Second*2).Should(BeTrue())
+
+			var hnewsCreated hnewsv1.HNews
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "hnews-sample", Namespace: "default"}, &hnewsCreated)).Should(Succeed())
+			Expect(hnewsCreated.Spec).To(Equal(hnewsv1.HNewsSpec{}))
+			Expect(hnewsCreated.Generation).To(BeEquivalentTo(1))
+		})
+
+		It("It should only return articles of the type in the `filter`", func() {
+			By("By looking for jobs in the job feed by default")
+			ctx := context.Background()
+			hnews := &hnewsv1.HNews{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "hnews-jobs",
+					Namespace: "default",
+				},
+				Spec: hnewsv1.HNewsSpec{
+					Filter: hnewsv1.Filter{
+						Type:        string(hnewsv1.Job),
+						Limit:       5,
+						Score:       ">=0",
+						Descendants: ">=0",
+					},
+				},
+			}
+
+			Expect(k8sClient.Create(ctx, hnews)).Should(Succeed())
+
+			Eventually(func() []hnewsv1.Link {
+				var hnewsCreated hnewsv1.HNews
+				err := k8sClient.Get(ctx, types.NamespacedName{Name: "hnews-jobs", Namespace: "default"}, &hnewsCreated)
+				Expect(err).NotTo(HaveOccurred())
+				return hnewsCreated.Status.Links
+			}, time.Second*30, time.Second*2).Should(ConsistOf(HaveField("HNewsUrl", "https://news.ycombinator.com/item?id=3")))
+
+			By("By reporting feeds which can't contain the type")
+			var hnewsCreated hnewsv1.HNews
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "hnews-jobs", Namespace: "default"}, &hnewsCreated)).Should(Succeed())
+			hnewsCreated.Spec.Feed = hnewsv1.ShowFeed
+			Expect(k8sClient.Update(ctx, &hnewsCreated)).Should(Succeed())
 
+			Eventually(func() bool {
+				var hnewsUpdated hnewsv1.HNews
+				err := k8sClient.Get(ctx, types.NamespacedName{Name: "hnews-jobs", Namespace: "default"}, &hnewsUpdated)
+				Expect(err).NotTo(HaveOccurred())
+				return meta.IsStatusConditionTrue(hnewsUpdated.Status.Conditions, hnewsv1.ConditionInvalidSpec) &&
+					len(hnewsUpdated.Status.Links) == 0
 			}, time.Second*30, time.Second*2).Should(BeTrue())
 		})
 
+		It("It should only return articles which match the `expression` in the `filter`", func() {
+			By("By combining fields of the articles")
+			ctx := context.Background()
+			hnews := &hnewsv1.HNews{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "hnews-expression",
+					Namespace: "default",
+				},
+				Spec: hnewsv1.HNewsSpec{
+					Filter: hnewsv1.Filter{
+						Type:        string(hnewsv1.Story),
+						Limit:       5,
+						Score:       ">=0",
+						Descendants: ">=0",
+						Expression:  `item.score > 400 || item.title.startsWith("Ask HN")`,
+					},
+				},
+			}
+
+			Expect(k8sClient.Create(ctx, hnews)).Should(Succeed())
+
+			Eventually(func() []hnewsv1.Link {
+				var hnewsCreated hnewsv1.HNews
+				err := k8sClient.Get(ctx, types.NamespacedName{Name: "hnews-expression", Namespace: "default"}, &hnewsCreated)
+				Expect(err).NotTo(HaveOccurred())
+				return hnewsCreated.Status.Links
+			}, time.Second*30, time.Second*2).Should(ConsistOf(HaveField("ID", 1), HaveField("ID", 4)))
+
+			By("By reporting expressions which don't compile")
+			var hnewsCreated hnewsv1.HNews
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "hnews-expression", Namespace: "default"}, &hnewsCreated)).Should(Succeed())
+			hnewsCreated.Spec.Filter.Expression = `item.points > 100`
+			Expect(k8sClient.Update(ctx, &hnewsCreated)).Should(Succeed())
+
+			Eventually(func() string {
+				var hnewsUpdated hnewsv1.HNews
+				err := k8sClient.Get(ctx, types.NamespacedName{Name: "hnews-expression", Namespace: "default"}, &hnewsUpdated)
+				Expect(err).NotTo(HaveOccurred())
+				condition := meta.FindStatusCondition(hnewsUpdated.Status.Conditions, hnewsv1.ConditionInvalidSpec)
+				if condition == nil || condition.Status != metav1.ConditionTrue {
+					return ""
+				}
+				return condition.Reason
+			}, time.Second*30, time.Second*2).Should(Equal(hnewsv1.ReasonExpressionCompileError))
+		})
+
+		It("It should report comparisons in the `filter` which can't be parsed", func() {
+			ctx := context.Background()
+			hnews := &hnewsv1.HNews{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "hnews-invalid-score",
+					Namespace: "default",
+				},
+				Spec: hnewsv1.HNewsSpec{
+					Filter: hnewsv1.Filter{
+						Type:        string(hnewsv1.Story),
+						Limit:       5,
+						Score:       "abc",
+						Descendants: "between 0 and 1000",
+					},
+				},
+			}
+
+			Expect(k8sClient.Create(ctx, hnews)).Should(Succeed())
+
+			Eventually(func() string {
+				var hnewsCreated hnewsv1.HNews
+				err := k8sClient.Get(ctx, types.NamespacedName{Name: "hnews-invalid-score", Namespace: "default"}, &hnewsCreated)
+				Expect(err).NotTo(HaveOccurred())
+				condition := meta.FindStatusCondition(hnewsCreated.Status.Conditions, hnewsv1.ConditionInvalidSpec)
+				if condition == nil || condition.Status != metav1.ConditionTrue {
+					return ""
+				}
+				return condition.Reason
+			}, time.Second*30, time.Second*2).Should(Equal(hnewsv1.ReasonInvalidComparison))
+		})
+
+		It("It should report if the current `spec` is synced with conditions", func() {
+			ctx := context.Background()
+			hnews := &hnewsv1.HNews{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "hnews-conditions",
+					Namespace: "default",
+				},
+				Spec: hnewsv1.HNewsSpec{
+					Filter: hnewsv1.Filter{
+						Type:        string(hnewsv1.Story),
+						Limit:       5,
+						Score:       ">=0",
+						Descendants: ">=0",
+					},
+				},
+			}
+
+			Expect(k8sClient.Create(ctx, hnews)).Should(Succeed())
+
+			Eventually(func() bool {
+				var hnewsCreated hnewsv1.HNews
+				err := k8sClient.Get(ctx, types.NamespacedName{Name: "hnews-conditions", Namespace: "default"}, &hnewsCreated)
+				Expect(err).NotTo(HaveOccurred())
+				return hnewsCreated.Status.ObservedGeneration == hnewsCreated.Generation &&
+					meta.IsStatusConditionTrue(hnewsCreated.Status.Conditions, hnewsv1.ConditionReady) &&
+					meta.IsStatusConditionTrue(hnewsCreated.Status.Conditions, hnewsv1.ConditionSynced) &&
+					meta.IsStatusConditionFalse(hnewsCreated.Status.Conditions, hnewsv1.ConditionDegraded) &&
+					meta.IsStatusConditionFalse(hnewsCreated.Status.Conditions, hnewsv1.ConditionInvalidSpec)
+			}, time.Second*30, time.Second*2).Should(BeTrue())
+
+			By("By not being ready while the `spec` can't be synced")
+			var hnewsCreated hnewsv1.HNews
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "hnews-conditions", Namespace: "default"}, &hnewsCreated)).Should(Succeed())
+			hnewsCreated.Spec.Filter.Score = ">="
+			Expect(k8sClient.Update(ctx, &hnewsCreated)).Should(Succeed())
+
+			Eventually(func() bool {
+				var hnewsUpdated hnewsv1.HNews
+				err := k8sClient.Get(ctx, types.NamespacedName{Name: "hnews-conditions", Namespace: "default"}, &hnewsUpdated)
+				Expect(err).NotTo(HaveOccurred())
+				ready := meta.FindStatusCondition(hnewsUpdated.Status.Conditions, hnewsv1.ConditionReady)
+				return hnewsUpdated.Status.ObservedGeneration == hnewsUpdated.Generation &&
+					ready != nil && ready.Status == metav1.ConditionFalse && ready.Reason == hnewsv1.ReasonInvalidComparison
+			}, time.Second*30, time.Second*2).Should(BeTrue())
+		})
+
+		It("It should only return articles with the keywords in the `title` filter", func() {
+			ctx := context.Background()
+			hnews := &hnewsv1.HNews{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "hnews-title",
+					Namespace: "default",
+				},
+				Spec: hnewsv1.HNewsSpec{
+					Filter: hnewsv1.Filter{
+						Type:        string(hnewsv1.Story),
+						Limit:       5,
+						Score:       ">=0",
+						Descendants: ">=0",
+						Title: &hnewsv1.TitleFilter{
+							Include:      []string{"kubernetes", "working"},
+							ExcludeRegex: []string{"^Ask HN"},
+						},
+					},
+				},
+			}
+
+			Expect(k8sClient.Create(ctx, hnews)).Should(Succeed())
+
+			Eventually(func() []hnewsv1.Link {
+				var hnewsCreated hnewsv1.HNews
+				err := k8sClient.Get(ctx, types.NamespacedName{Name: "hnews-title", Namespace: "default"}, &hnewsCreated)
+				Expect(err).NotTo(HaveOccurred())
+				return hnewsCreated.Status.Links
+			}, time.Second*30, time.Second*2).Should(ConsistOf(HaveField("Title", "Show HN: A Kubernetes operator")))
+		})
+
+		It("It should only return articles on the domains in the `domains` filter", func() {
+			By("By including the articles on a domain")
+			ctx := context.Background()
+			hnews := &hnewsv1.HNews{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "hnews-domains",
+					Namespace: "default",
+				},
+				Spec: hnewsv1.HNewsSpec{
+					Filter: hnewsv1.Filter{
+						Type:        string(hnewsv1.Story),
+						Limit:       5,
+						Score:       ">=0",
+						Descendants: ">=0",
+						Domains: &hnewsv1.DomainFilter{
+							Include:   []string{"github.com"},
+							SelfPosts: hnewsv1.ExcludeSelfPosts,
+						},
+					},
+				},
+			}
+
+			Expect(k8sClient.Create(ctx, hnews)).Should(Succeed())
+
+			Eventually(func() []hnewsv1.Link {
+				var hnewsCreated hnewsv1.HNews
+				err := k8sClient.Get(ctx, types.NamespacedName{Name: "hnews-domains", Namespace: "default"}, &hnewsCreated)
+				Expect(err).NotTo(HaveOccurred())
+				return hnewsCreated.Status.Links
+			}, time.Second*30, time.Second*2).Should(ConsistOf(HaveField("ArticleUrl", "https://github.com/vadasambar/hnews")))
+
+			By("By only including self posts")
+			var hnewsCreated hnewsv1.HNews
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "hnews-domains", Namespace: "default"}, &hnewsCreated)).Should(Succeed())
+			hnewsCreated.Spec.Filter.Domains = &hnewsv1.DomainFilter{SelfPosts: hnewsv1.OnlySelfPosts}
+			Expect(k8sClient.Update(ctx, &hnewsCreated)).Should(Succeed())
+
+			Eventually(func() []hnewsv1.Link {
+				var hnewsUpdated hnewsv1.HNews
+				err := k8sClient.Get(ctx, types.NamespacedName{Name: "hnews-domains", Namespace: "default"}, &hnewsUpdated)
+				Expect(err).NotTo(HaveOccurred())
+				return hnewsUpdated.Status.Links
+			}, time.Second*30, time.Second*2).Should(ConsistOf(HaveField("ID", 4)))
+		})
+
+		It("It should only return articles by the authors in the `author` filter", func() {
+			By("By leaving out an author")
+			ctx := context.Background()
+			hnews := &hnewsv1.HNews{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "hnews-author",
+					Namespace: "default",
+				},
+				Spec: hnewsv1.HNewsSpec{
+					Filter: hnewsv1.Filter{
+						Type:        string(hnewsv1.Story),
+						Limit:       5,
+						Score:       ">=0",
+						Descendants: ">=0",
+						Author: &hnewsv1.AuthorFilter{
+							Exclude: []string{"alice"},
+						},
+					},
+				},
+			}
+
+			Expect(k8sClient.Create(ctx, hnews)).Should(Succeed())
+
+			Eventually(func() []hnewsv1.Link {
+				var hnewsCreated hnewsv1.HNews
+				err := k8sClient.Get(ctx, types.NamespacedName{Name: "hnews-author", Namespace: "default"}, &hnewsCreated)
+				Expect(err).NotTo(HaveOccurred())
+				return hnewsCreated.Status.Links
+			}, time.Second*30, time.Second*2).Should(ConsistOf(HaveField("ID", 2), HaveField("ID", 4)))
+
+			By("By leaving out new accounts and accounts with little karma")
+			var hnewsCreated hnewsv1.HNews
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "hnews-author", Namespace: "default"}, &hnewsCreated)).Should(Succeed())
+			hnewsCreated.Spec.Filter.Author = &hnewsv1.AuthorFilter{
+				Karma:         ">=100",
+				MinAccountAge: &metav1.Duration{Duration: 30 * 24 * time.Hour},
+			}
+			Expect(k8sClient.Update(ctx, &hnewsCreated)).Should(Succeed())
+
+			Eventually(func() []hnewsv1.Link {
+				var hnewsUpdated hnewsv1.HNews
+				err := k8sClient.Get(ctx, types.NamespacedName{Name: "hnews-author", Namespace: "default"}, &hnewsUpdated)
+				Expect(err).NotTo(HaveOccurred())
+				return hnewsUpdated.Status.Links
+			}, time.Second*30, time.Second*2).Should(ConsistOf(HaveField("ID", 1), HaveField("ID", 2)))
+		})
+
+		It("It should only return articles posted in the time window of the `filter`", func() {
+			By("By only including articles posted in the last 12 hours")
+			ctx := context.Background()
+			hnews := &hnewsv1.HNews{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "hnews-time",
+					Namespace: "default",
+				},
+				Spec: hnewsv1.HNewsSpec{
+					Filter: hnewsv1.Filter{
+						Type:        string(hnewsv1.Story),
+						Limit:       5,
+						Score:       ">=0",
+						Descendants: ">=0",
+						MaxAge:      &metav1.Duration{Duration: 12 * time.Hour},
+					},
+				},
+			}
+
+			Expect(k8sClient.Create(ctx, hnews)).Should(Succeed())
+
+			Eventually(func() []hnewsv1.Link {
+				var hnewsCreated hnewsv1.HNews
+				err := k8sClient.Get(ctx, types.NamespacedName{Name: "hnews-time", Namespace: "default"}, &hnewsCreated)
+				Expect(err).NotTo(HaveOccurred())
+				return hnewsCreated.Status.Links
+			}, time.Second*30, time.Second*2).Should(ConsistOf(HaveField("ID", 1), HaveField("ID", 2)))
+
+			By("By only including articles posted between since and until")
+			var hnewsCreated hnewsv1.HNews
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "hnews-time", Namespace: "default"}, &hnewsCreated)).Should(Succeed())
+			since, until := metav1.NewTime(testNow.Add(-6*time.Hour)), metav1.NewTime(testNow.Add(-2*time.Hour))
+			hnewsCreated.Spec.Filter.MaxAge = nil
+			hnewsCreated.Spec.Filter.Since = &since
+			hnewsCreated.Spec.Filter.Until = &until
+			Expect(k8sClient.Update(ctx, &hnewsCreated)).Should(Succeed())
+
+			Eventually(func() []hnewsv1.Link {
+				var hnewsUpdated hnewsv1.HNews
+				err := k8sClient.Get(ctx, types.NamespacedName{Name: "hnews-time", Namespace: "default"}, &hnewsUpdated)
+				Expect(err).NotTo(HaveOccurred())
+				return hnewsUpdated.Status.Links
+			}, time.Second*30, time.Second*2).Should(ConsistOf(HaveField("ID", 2)))
+		})
+
+		It("It should sort all the matching articles by `spec.sort` before keeping `limit` of them", func() {
+			ctx := context.Background()
+			hnews := &hnewsv1.HNews{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "hnews-sort",
+					Namespace: "default",
+				},
+				Spec: hnewsv1.HNewsSpec{
+					Sort: hnewsv1.SortByScore,
+					Filter: hnewsv1.Filter{
+						Type:        string(hnewsv1.Story),
+						Limit:       2,
+						Score:       ">=0",
+						Descendants: ">=0",
+					},
+				},
+			}
+
+			Expect(k8sClient.Create(ctx, hnews)).Should(Succeed())
+
+			Eventually(func() [][2]int {
+				var hnewsCreated hnewsv1.HNews
+				err := k8sClient.Get(ctx, types.NamespacedName{Name: "hnews-sort", Namespace: "default"}, &hnewsCreated)
+				Expect(err).NotTo(HaveOccurred())
+				idsAndRanks := [][2]int{}
+				for _, link := range hnewsCreated.Status.Links {
+					idsAndRanks = append(idsAndRanks, [2]int{link.ID, link.Rank})
+				}
+				return idsAndRanks
+			}, time.Second*30, time.Second*2).Should(Equal([][2]int{{1, 1}, {4, 4}}))
+		})
+
+		It("It should put the links which don't fit in the status in `HNewsResult` pages", func() {
+			ctx := context.Background()
+			// the HNews isn't created so that the controller doesn't sync its pages too
+			hnews := &hnewsv1.HNews{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: "apps.vadasambar.com/v1",
+					Kind:       "HNews",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "hnews-results",
+					Namespace: "default",
+					UID:       "3f2d9b4e-3c1a-4c55-9a57-5d2c3e0f6b1a",
+				},
+			}
+
+			links := func(n int) []hnewsv1.Link {
+				links := []hnewsv1.Link{}
+				for id := 1; id <= n; id++ {
+					links = append(links, hnewsv1.Link{ID: id, Rank: id})
+				}
+				return links
+			}
+			pages := func() []hnewsv1.HNewsResult {
+				var results hnewsv1.HNewsResultList
+				Expect(k8sClient.List(ctx, &results, client.InNamespace("default"),
+					client.MatchingLabels{hnewsv1.HNewsUIDLabel: string(hnews.UID)})).Should(Succeed())
+				return results.Items
+			}
+			r := &HNewsReconciler{Client: k8sClient, Scheme: k8sClient.Scheme()}
+
+			By("By writing 250 links to 3 pages")
+			Expect(r.syncResults(ctx, hnews, links(250))).Should(Succeed())
+			Expect(hnews.Status.Links).To(HaveLen(hnewsv1.MaxInlineLinks))
+			Expect(hnews.Status.Results).To(Equal(&hnewsv1.ResultsRef{
+				Count: 250,
+				Pages: []string{"hnews-results-0", "hnews-results-1", "hnews-results-2"},
+			}))
+			Eventually(pages, time.Second*10, time.Millisecond*250).Should(HaveLen(3))
+			var last hnewsv1.HNewsResult
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "hnews-results-2", Namespace: "default"}, &last)).Should(Succeed())
+			Expect(last.Page).To(Equal(2))
+			Expect(last.Links).To(HaveLen(50))
+			Expect(last.Links[0].ID).To(Equal(201))
+			Expect(metav1.IsControlledBy(&last, hnews)).To(BeTrue())
+
+			By("By deleting the pages which aren't needed anymore")
+			Expect(r.syncResults(ctx, hnews, links(150))).Should(Succeed())
+			Expect(hnews.Status.Results.Pages).To(HaveLen(2))
+			Eventually(pages, time.Second*10, time.Millisecond*250).Should(HaveLen(2))
+
+			Expect(r.syncResults(ctx, hnews, links(5))).Should(Succeed())
+			Expect(hnews.Status.Links).To(HaveLen(5))
+			Expect(hnews.Status.Results).To(BeNil())
+			Eventually(pages, time.Second*10, time.Millisecond*250).Should(BeEmpty())
+		})
+
+		It("It should report the articles which weren't in the previous syncs in `newOnly` mode", func() {
+			By("By reporting every article in the first sync")
+			ctx := context.Background()
+			hnews := &hnewsv1.HNews{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "hnews-new-only",
+					Namespace: "default",
+				},
+				Spec: hnewsv1.HNewsSpec{
+					Mode: hnewsv1.ModeNewOnly,
+					Filter: hnewsv1.Filter{
+						Type:        string(hnewsv1.Story),
+						Limit:       5,
+						Score:       ">=100",
+						Descendants: ">=0",
+					},
+				},
+			}
+
+			Expect(k8sClient.Create(ctx, hnews)).Should(Succeed())
+
+			Eventually(func() []hnewsv1.Link {
+				var hnewsCreated hnewsv1.HNews
+				err := k8sClient.Get(ctx, types.NamespacedName{Name: "hnews-new-only", Namespace: "default"}, &hnewsCreated)
+				Expect(err).NotTo(HaveOccurred())
+				return hnewsCreated.Status.NewLinks
+			}, time.Second*30, time.Second*2).Should(ConsistOf(HaveField("ID", 1), HaveField("ID", 4)))
+
+			By("By only reporting the articles which weren't in the first sync")
+			var hnewsCreated hnewsv1.HNews
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "hnews-new-only", Namespace: "default"}, &hnewsCreated)).Should(Succeed())
+			hnewsCreated.Spec.Filter.Score = ">=0"
+			Expect(k8sClient.Update(ctx, &hnewsCreated)).Should(Succeed())
+
+			Eventually(func() []hnewsv1.Link {
+				var hnewsUpdated hnewsv1.HNews
+				err := k8sClient.Get(ctx, types.NamespacedName{Name: "hnews-new-only", Namespace: "default"}, &hnewsUpdated)
+				Expect(err).NotTo(HaveOccurred())
+				return hnewsUpdated.Status.NewLinks
+			}, time.Second*30, time.Second*2).Should(ConsistOf(HaveField("ID", 2)))
+
+			var hnewsUpdated hnewsv1.HNews
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "hnews-new-only", Namespace: "default"}, &hnewsUpdated)).Should(Succeed())
+			Expect(hnewsUpdated.Status.SeenIDs).To(ConsistOf(1, 2, 4))
+			Expect(hnewsUpdated.Status.Links).To(ConsistOf(
+				And(HaveField("ID", 1), HaveField("New", false)),
+				And(HaveField("ID", 2), HaveField("New", true)),
+				And(HaveField("ID", 4), HaveField("New", false)),
+			))
+		})
+
+		It("It should track the velocity of the articles and filter by it", func() {
+			ctx := context.Background()
+			hnews := &hnewsv1.HNews{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "hnews-velocity",
+					Namespace: "default",
+				},
+				Spec: hnewsv1.HNewsSpec{
+					Filter: hnewsv1.Filter{
+						Type:        string(hnewsv1.Story),
+						Limit:       5,
+						Score:       ">=0",
+						Descendants: ">=0",
+						Velocity:    ">100",
+					},
+				},
+			}
+
+			Expect(k8sClient.Create(ctx, hnews)).Should(Succeed())
+
+			// the article with id 1 got 500 points and 120 comments in the hour since it was posted
+			Eventually(func() []hnewsv1.Link {
+				var hnewsCreated hnewsv1.HNews
+				err := k8sClient.Get(ctx, types.NamespacedName{Name: "hnews-velocity", Namespace: "default"}, &hnewsCreated)
+				Expect(err).NotTo(HaveOccurred())
+				return hnewsCreated.Status.Links
+			}, time.Second*30, time.Second*2).Should(ConsistOf(And(HaveField("ID", 1), HaveField("PeakScore", 500))))
+
+			var hnewsSynced hnewsv1.HNews
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "hnews-velocity", Namespace: "default"}, &hnewsSynced)).Should(Succeed())
+			link := hnewsSynced.Status.Links[0]
+			Expect(link.FirstSeenAt.Time).To(BeTemporally("==", testNow))
+			Expect(link.Velocity.Points).To(Equal(500))
+			Expect(link.Velocity.Comments).To(Equal(120))
+		})
+
+		It("It should add the comments picked by `spec.comments` to the links", func() {
+			ctx := context.Background()
+			hnews := &hnewsv1.HNews{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "hnews-comments",
+					Namespace: "default",
+				},
+				Spec: hnewsv1.HNewsSpec{
+					Comments: &hnewsv1.CommentsSpec{Count: 2, Depth: 2},
+					Filter: hnewsv1.Filter{
+						Type:        string(hnewsv1.Story),
+						Limit:       1,
+						Score:       ">=0",
+						Descendants: ">=0",
+					},
+				},
+			}
+
+			Expect(k8sClient.Create(ctx, hnews)).Should(Succeed())
+
+			// the deleted comment is skipped and the reply comes right after its parent
+			Eventually(func() []hnewsv1.LinkComment {
+				var hnewsCreated hnewsv1.HNews
+				err := k8sClient.Get(ctx, types.NamespacedName{Name: "hnews-comments", Namespace: "default"}, &hnewsCreated)
+				Expect(err).NotTo(HaveOccurred())
+				if len(hnewsCreated.Status.Links) == 0 {
+					return nil
+				}
+				return hnewsCreated.Status.Links[0].Comments
+			}, time.Second*30, time.Second*2).Should(Equal([]hnewsv1.LinkComment{
+				{ID: 101, Parent: 1, Depth: 1, By: "eve", Text: "Neat & tidy.\n\nDoes it scale?"},
+				{ID: 104, Parent: 101, Depth: 2, By: "frank", Text: "It does"},
+				{ID: 103, Parent: 1, Depth: 1, By: "grace", Text: "Nice"},
+			}))
+		})
+
+		It("It should find the comments which match the filter in the threads of the stories", func() {
+			ctx := context.Background()
+			hnews := &hnewsv1.HNews{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "hnews-threads",
+					Namespace: "default",
+				},
+				Spec: hnewsv1.HNewsSpec{
+					Threads: &hnewsv1.ThreadsSpec{Depth: 2, MaxItems: 100},
+					Filter: hnewsv1.Filter{
+						Type:  string(hnewsv1.Comment),
+						Limit: 5,
+						// score and descendents don't apply to comments
+						Score:       ">200",
+						Descendants: ">5",
+						Author:      &hnewsv1.AuthorFilter{Exclude: []string{"grace"}},
+						Text:        &hnewsv1.TextFilter{MinLength: 5},
+					},
+				},
+			}
+
+			Expect(k8sClient.Create(ctx, hnews)).Should(Succeed())
+
+			// the deleted and dead comments are skipped and the reply comes right after its parent
+			Eventually(func() []hnewsv1.Link {
+				var hnewsCreated hnewsv1.HNews
+				err := k8sClient.Get(ctx, types.NamespacedName{Name: "hnews-threads", Namespace: "default"}, &hnewsCreated)
+				Expect(err).NotTo(HaveOccurred())
+				return hnewsCreated.Status.Links
+			}, time.Second*30, time.Second*2).Should(HaveLen(2))
+
+			var hnewsCreated hnewsv1.HNews
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "hnews-threads", Namespace: "default"}, &hnewsCreated)).To(Succeed())
+			story := &hnewsv1.LinkStory{
+				ID:         1,
+				Title:      "Show HN: A Kubernetes operator",
+				HNewsUrl:   "https://news.ycombinator.com/item?id=1",
+				ArticleUrl: "https://github.com/vadasambar/hnews",
+			}
+			links := hnewsCreated.Status.Links
+			Expect(links[0].HNewsUrl).To(Equal("https://news.ycombinator.com/item?id=101"))
+			Expect(links[0].Rank).To(Equal(1))
+			Expect(links[0].Comment).To(Equal(&hnewsv1.LinkComment{ID: 101, Parent: 1, Depth: 1, By: "eve", Text: "Neat & tidy.\n\nDoes it scale?"}))
+			Expect(links[0].Story).To(Equal(story))
+			Expect(links[1].HNewsUrl).To(Equal("https://news.ycombinator.com/item?id=104"))
+			Expect(links[1].Rank).To(Equal(2))
+			Expect(links[1].Comment).To(Equal(&hnewsv1.LinkComment{ID: 104, Parent: 101, Depth: 2, By: "frank", Text: "It does"}))
+			Expect(links[1].Story).To(Equal(story))
+		})
+
+		It("It should merge the feeds in order and only look at an article once", func() {
+			ctx := context.Background()
+			hnews := &hnewsv1.HNews{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "hnews-feeds",
+					Namespace: "default",
+				},
+				Spec: hnewsv1.HNewsSpec{
+					// ask has 4 and 1, which are in top as well
+					Feeds: []hnewsv1.Feed{hnewsv1.AskFeed, hnewsv1.TopFeed},
+					Filter: hnewsv1.Filter{
+						Type:        string(hnewsv1.Story),
+						Limit:       10,
+						Score:       ">=0",
+						Descendants: ">=0",
+					},
+				},
+			}
+
+			Expect(k8sClient.Create(ctx, hnews)).Should(Succeed())
+
+			Eventually(func() [][2]int {
+				var hnewsCreated hnewsv1.HNews
+				err := k8sClient.Get(ctx, types.NamespacedName{Name: "hnews-feeds", Namespace: "default"}, &hnewsCreated)
+				Expect(err).NotTo(HaveOccurred())
+				ranks := [][2]int{}
+				for _, link := range hnewsCreated.Status.Links {
+					ranks = append(ranks, [2]int{link.ID, link.Rank})
+				}
+				return ranks
+			}, time.Second*30, time.Second*2).Should(Equal([][2]int{{4, 1}, {1, 2}, {2, 3}}))
+		})
+
+		It("It should schedule the next sync after `spec.syncInterval` with a bit of jitter", func() {
+			ctx := context.Background()
+			hnews := &hnewsv1.HNews{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "hnews-interval",
+					Namespace: "default",
+				},
+				Spec: hnewsv1.HNewsSpec{
+					SyncInterval: &metav1.Duration{Duration: 10 * time.Minute},
+				},
+			}
+
+			Expect(k8sClient.Create(ctx, hnews)).Should(Succeed())
+
+			nextSyncIn := func(name string) func() time.Duration {
+				return func() time.Duration {
+					var hnewsCreated hnewsv1.HNews
+					err := k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: "default"}, &hnewsCreated)
+					Expect(err).NotTo(HaveOccurred())
+					if hnewsCreated.Status.LastSyncedAt.IsZero() {
+						return 0
+					}
+					return hnewsCreated.Status.NextSyncAt.Sub(hnewsCreated.Status.LastSyncedAt.Time)
+				}
+			}
+			// the times in the status are rounded to the second
+			Eventually(nextSyncIn("hnews-interval"), time.Second*30, time.Second*2).Should(
+				And(BeNumerically(">=", 10*time.Minute-time.Second), BeNumerically("<=", 11*time.Minute+time.Second)))
+
+			By("By syncing at the minimum interval when `spec.syncInterval` is lower")
+			hnews = &hnewsv1.HNews{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "hnews-interval-min",
+					Namespace: "default",
+				},
+				Spec: hnewsv1.HNewsSpec{
+					SyncInterval: &metav1.Duration{Duration: time.Second},
+				},
+			}
+
+			Expect(k8sClient.Create(ctx, hnews)).Should(Succeed())
+
+			Eventually(nextSyncIn("hnews-interval-min"), time.Second*30, time.Second*2).Should(
+				And(BeNumerically(">=", MinSyncInterval-time.Second), BeNumerically("<=", MinSyncInterval*11/10+time.Second)))
+
+			By("By spreading the syncs of HNews with the same interval")
+			r := &HNewsReconciler{DefaultSyncInterval: DefaultSyncInterval, MinSyncInterval: MinSyncInterval}
+			intervals := map[time.Duration]bool{}
+			for i := 0; i < 10; i++ {
+				interval := r.syncInterval(&hnewsv1.HNewsSpec{})
+				Expect(interval).To(And(BeNumerically(">=", DefaultSyncInterval), BeNumerically("<=", DefaultSyncInterval*11/10)))
+				intervals[interval] = true
+			}
+			Expect(len(intervals)).To(BeNumerically(">", 1))
+		})
 	})
 })
@@ -0,0 +1,200 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	appsv1 "github.com/vadasambar/hnews/api/v1"
+)
+
+const (
+	// resultPageSize is the number of links in an HNewsResult page
+	resultPageSize = 100
+	// resultPageBytes is the size of the links in an HNewsResult page.
+	// The pages have fewer links when they are big (e.g., with comments),
+	// so that they stay well under the size limit of etcd objects.
+	resultPageBytes = 512 * 1024
+	// inlineLinksBytes is the size of the links kept in the status of an HNews
+	// and of its new links, on top of appsv1.MaxInlineLinks
+	inlineLinksBytes = 256 * 1024
+)
+
+// fitLinks returns the number of the first links which fit in max links
+// and budget bytes. The first link always fits, so that they all end up
+// somewhere.
+func fitLinks(links []appsv1.Link, max, budget int) int {
+	n := 0
+	for n < len(links) && n < max {
+		size := linkSize(&links[n])
+		if n > 0 && size > budget {
+			break
+		}
+		budget -= size
+		n++
+	}
+	return n
+}
+
+// linkSize returns the size of a link in the status or a page
+func linkSize(link *appsv1.Link) int {
+	data, err := json.Marshal(link)
+	if err != nil {
+		return 0
+	}
+	return len(data)
+}
+
+// syncResults puts the links in the status of hn. When there are more of them
+// than fit there, the first ones are kept in the status and all of them
+// are written to HNewsResult pages owned by hn. The pages which aren't
+// needed anymore are deleted.
+func (r *HNewsReconciler) syncResults(ctx context.Context, hn *appsv1.HNews, links []appsv1.Link) error {
+	hn.Status.Links = links
+	hn.Status.Results = nil
+	pages := []string{}
+	if inline := fitLinks(links, appsv1.MaxInlineLinks, inlineLinksBytes); inline < len(links) {
+		hn.Status.Links = links[:inline]
+		for page, start := 0, 0; start < len(links); page++ {
+			end := start + fitLinks(links[start:], resultPageSize, resultPageBytes)
+			result := &appsv1.HNewsResult{ObjectMeta: metav1.ObjectMeta{
+				Name:      resultPageName(hn.Name, page),
+				Namespace: hn.Namespace,
+			}}
+			_, err := controllerutil.CreateOrUpdate(ctx, r.Client, result, func() error {
+				if result.Labels == nil {
+					result.Labels = map[string]string{}
+				}
+				result.Labels[appsv1.HNewsUIDLabel] = string(hn.UID)
+				result.HNews = hn.Name
+				result.Page = page
+				result.Links = links[start:end]
+				// stored before the write, its event can come before CreateOrUpdate returns
+				r.pages.Store(client.ObjectKeyFromObject(result), pageDigest(result))
+				return controllerutil.SetControllerReference(hn, result, r.Scheme)
+			})
+			if err != nil {
+				return fmt.Errorf("unable to write hnews result page %s: %w", result.Name, err)
+			}
+			pages = append(pages, result.Name)
+			start = end
+		}
+		hn.Status.Results = &appsv1.ResultsRef{Count: len(links), Pages: pages}
+	}
+
+	var results appsv1.HNewsResultList
+	if err := r.List(ctx, &results, client.InNamespace(hn.Namespace), client.MatchingLabels{appsv1.HNewsUIDLabel: string(hn.UID)}); err != nil {
+		return fmt.Errorf("unable to list hnews result pages: %w", err)
+	}
+	for i := range results.Items {
+		result := &results.Items[i]
+		if result.Page < len(pages) || !metav1.IsControlledBy(result, hn) {
+			continue
+		}
+		r.pages.Store(client.ObjectKeyFromObject(result), deletedPage)
+		if err := r.Delete(ctx, result); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("unable to delete hnews result page %s: %w", result.Name, err)
+		}
+	}
+	return nil
+}
+
+// resultPageName returns the name of a page of the links of the HNews.
+// The name of the HNews is truncated, and a hash of it added so that
+// the names of the pages of different HNews don't collide, when it's too long.
+func resultPageName(hnName string, page int) string {
+	suffix := fmt.Sprintf("-%d", page)
+	if len(hnName)+len(suffix) <= validation.DNS1123SubdomainMaxLength {
+		return hnName + suffix
+	}
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(hnName))
+	suffix = fmt.Sprintf("-%08x%s", hash.Sum32(), suffix)
+	// names can't have a "-" or a "." right before the suffix
+	return strings.TrimRight(hnName[:validation.DNS1123SubdomainMaxLength-len(suffix)], "-.") + suffix
+}
+
+// deletedPage is stored in HNewsReconciler.pages for the pages the controller deletes
+const deletedPage = ""
+
+// pageDigest returns a digest of what the controller writes in an HNewsResult page
+func pageDigest(result *appsv1.HNewsResult) string {
+	hash := fnv.New64a()
+	_ = json.NewEncoder(hash).Encode([]interface{}{
+		result.Labels[appsv1.HNewsUIDLabel], result.HNews, result.Page, result.Links,
+	})
+	return fmt.Sprintf("%016x", hash.Sum64())
+}
+
+// pageChangedByOthers filters out the events of the HNewsResult pages which
+// were written or deleted by the controller itself, so that only the pages
+// edited or deleted by someone else trigger a sync of their HNews
+func (r *HNewsReconciler) pageChangedByOthers() predicate.Predicate {
+	return predicate.Funcs{
+		// the pages which exist when the controller starts are created by it
+		CreateFunc: func(e event.CreateEvent) bool {
+			return false
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			result, ok := e.ObjectNew.(*appsv1.HNewsResult)
+			if !ok {
+				return false
+			}
+			written, ok := r.pages.Load(client.ObjectKeyFromObject(result))
+			return !ok || written != pageDigest(result)
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			written, ok := r.pages.LoadAndDelete(client.ObjectKeyFromObject(e.Object))
+			return !ok || written != deletedPage
+		},
+	}
+}
+
+// previousLinks returns all the links of the last sync of hn,
+// including the ones which are only in its result pages
+func (r *HNewsReconciler) previousLinks(ctx context.Context, hn *appsv1.HNews) ([]appsv1.Link, error) {
+	if hn.Status.Results == nil {
+		return hn.Status.Links, nil
+	}
+
+	var results appsv1.HNewsResultList
+	if err := r.List(ctx, &results, client.InNamespace(hn.Namespace), client.MatchingLabels{appsv1.HNewsUIDLabel: string(hn.UID)}); err != nil {
+		return nil, fmt.Errorf("unable to list hnews result pages: %w", err)
+	}
+	sort.Slice(results.Items, func(i, j int) bool {
+		return results.Items[i].Page < results.Items[j].Page
+	})
+	links := []appsv1.Link{}
+	for i := range results.Items {
+		if metav1.IsControlledBy(&results.Items[i], hn) {
+			links = append(links, results.Items[i].Links...)
+		}
+	}
+	return links, nil
+}
@@ -0,0 +1,129 @@
+package controllers
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	appsv1 "github.com/vadasambar/hnews/api/v1"
+)
+
+func TestResultPageName(t *testing.T) {
+	if name := resultPageName("hnews-sample", 3); name != "hnews-sample-3" {
+		t.Errorf("expected hnews-sample-3, got %s", name)
+	}
+
+	long := strings.Repeat("a", validation.DNS1123SubdomainMaxLength-7) + "-b.c"
+	other := strings.Repeat("a", validation.DNS1123SubdomainMaxLength-7) + "-d.e"
+	for _, page := range []int{0, 42, 1000} {
+		name := resultPageName(long, page)
+		if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+			t.Errorf("%s: %v", name, errs)
+		}
+		if resultPageName(long, page) != name {
+			t.Errorf("expected %s to be stable", name)
+		}
+		if resultPageName(other, page) == name {
+			t.Errorf("expected the pages of %s and %s to have different names", long, other)
+		}
+	}
+}
+
+func TestSyncResultsBySize(t *testing.T) {
+	hn := &appsv1.HNews{ObjectMeta: metav1.ObjectMeta{Name: "big", Namespace: "default", UID: "9e3a6c2d-1b7f-4d5e-a0c8-3f4b2e1d6a97"}}
+	comments := make([]appsv1.LinkComment, maxLinkComments)
+	for i := range comments {
+		comments[i] = appsv1.LinkComment{ID: i + 1000, Text: strings.Repeat("x", maxLinkCommentsBytes/maxLinkComments)}
+	}
+	links := make([]appsv1.Link, 100)
+	for i := range links {
+		links[i] = appsv1.Link{ID: i + 1, Comments: comments}
+	}
+	c := newFakeClient(t, hn)
+	r := &HNewsReconciler{Client: c, Scheme: c.Scheme()}
+	if err := r.syncResults(context.Background(), hn, links); err != nil {
+		t.Fatal(err)
+	}
+
+	inline := 0
+	for i := range hn.Status.Links {
+		inline += linkSize(&hn.Status.Links[i])
+	}
+	if len(hn.Status.Links) >= appsv1.MaxInlineLinks || inline > inlineLinksBytes {
+		t.Errorf("got %d links (%d bytes) in the status; want fewer than %d in %d bytes", len(hn.Status.Links), inline, appsv1.MaxInlineLinks, inlineLinksBytes)
+	}
+	if hn.Status.Results == nil || hn.Status.Results.Count != len(links) {
+		t.Fatalf("got results %+v; want all the links in pages", hn.Status.Results)
+	}
+	var results appsv1.HNewsResultList
+	if err := c.List(context.Background(), &results, client.MatchingLabels{appsv1.HNewsUIDLabel: string(hn.UID)}); err != nil {
+		t.Fatal(err)
+	}
+	if len(results.Items) != len(hn.Status.Results.Pages) || len(results.Items) < 2 {
+		t.Fatalf("got %d pages; want the links split in the %d pages of the status", len(results.Items), len(hn.Status.Results.Pages))
+	}
+	paged := 0
+	for _, result := range results.Items {
+		size := 0
+		for i := range result.Links {
+			size += linkSize(&result.Links[i])
+		}
+		if size > resultPageBytes {
+			t.Errorf("got %d bytes in page %s; want at most %d", size, result.Name, resultPageBytes)
+		}
+		paged += len(result.Links)
+	}
+	if paged != len(links) {
+		t.Errorf("got %d links in the pages; want %d", paged, len(links))
+	}
+}
+
+func TestPageChangedByOthers(t *testing.T) {
+	hn := &appsv1.HNews{ObjectMeta: metav1.ObjectMeta{Name: "pages", Namespace: "default", UID: "5b0c1d7e-5f8a-4a0e-8d3c-0e6a2f9b7c41"}}
+	links := make([]appsv1.Link, appsv1.MaxInlineLinks+1)
+	for i := range links {
+		links[i] = appsv1.Link{ID: i + 1}
+	}
+	c := newFakeClient(t, hn)
+	r := &HNewsReconciler{Client: c, Scheme: c.Scheme()}
+	if err := r.syncResults(context.Background(), hn, links); err != nil {
+		t.Fatal(err)
+	}
+	pageChanged := r.pageChangedByOthers()
+
+	var results appsv1.HNewsResultList
+	if err := c.List(context.Background(), &results, client.MatchingLabels{appsv1.HNewsUIDLabel: string(hn.UID)}); err != nil {
+		t.Fatal(err)
+	}
+	if len(results.Items) != 1 {
+		t.Fatalf("expected 1 page, got %d", len(results.Items))
+	}
+	page := &results.Items[0]
+	if pageChanged.Create(event.CreateEvent{Object: page}) {
+		t.Error("expected the creation of a page not to trigger a sync")
+	}
+	if pageChanged.Update(event.UpdateEvent{ObjectOld: page, ObjectNew: page}) {
+		t.Error("expected a page written by the controller not to trigger a sync")
+	}
+	edited := page.DeepCopy()
+	edited.Links = edited.Links[1:]
+	if !pageChanged.Update(event.UpdateEvent{ObjectOld: page, ObjectNew: edited}) {
+		t.Error("expected a page edited by hand to trigger a sync")
+	}
+	if !pageChanged.Delete(event.DeleteEvent{Object: page}) {
+		t.Error("expected a page deleted by hand to trigger a sync")
+	}
+
+	// the page isn't needed anymore and is deleted by the controller
+	if err := r.syncResults(context.Background(), hn, links[:1]); err != nil {
+		t.Fatal(err)
+	}
+	if pageChanged.Delete(event.DeleteEvent{Object: page}) {
+		t.Error("expected a page deleted by the controller not to trigger a sync")
+	}
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	appsv1 "github.com/vadasambar/hnews/api/v1"
+)
+
+// markNew marks the links which aren't in seen as new and returns the first
+// appsv1.MaxInlineLinks of them (fewer when they don't fit in inlineLinksBytes)
+// along with the ids seen after the sync.
+// The ids of the links are moved to the end of seen, so that the ids
+// dropped to keep it at appsv1.MaxSeenIDs are the ones which weren't in
+// a sync for the longest and the links of the sync are never dropped.
+func markNew(links []appsv1.Link, seen []int) ([]appsv1.Link, []int) {
+	inSync := map[int]bool{}
+	for _, link := range links {
+		inSync[link.ID] = true
+	}
+	wasSeen := map[int]bool{}
+	after := []int{}
+	for _, id := range seen {
+		wasSeen[id] = true
+		if !inSync[id] {
+			after = append(after, id)
+		}
+	}
+
+	newLinks := []appsv1.Link{}
+	budget, full := inlineLinksBytes, false
+	for i := range links {
+		after = append(after, links[i].ID)
+		if wasSeen[links[i].ID] {
+			continue
+		}
+		links[i].New = true
+		if len(newLinks) >= appsv1.MaxInlineLinks || full {
+			continue
+		}
+		if size := linkSize(&links[i]); len(newLinks) > 0 && size > budget {
+			full = true
+		} else {
+			budget -= size
+			newLinks = append(newLinks, links[i])
+		}
+	}
+
+	if len(after) > appsv1.MaxSeenIDs {
+		after = after[len(after)-appsv1.MaxSeenIDs:]
+	}
+	return newLinks, after
+}
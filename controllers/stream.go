@@ -0,0 +1,209 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/utils/clock"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	appsv1 "github.com/vadasambar/hnews/api/v1"
+	"github.com/vadasambar/hnews/pkg/hnapi"
+)
+
+const (
+	// streamRetryMin and streamRetryMax bound the exponential backoff
+	// between reconnects of a stream which broke
+	streamRetryMin = time.Second
+	streamRetryMax = 5 * time.Minute
+	// streamPollInterval is how often a feed is polled while its stream is down
+	streamPollInterval = 30 * time.Second
+)
+
+// streamWatcher keeps a stream open to every feed and triggers a sync of the HNews
+// which select a feed as soon as it changes. While the stream of a feed is down,
+// the feed is polled instead until it is reconnected.
+type streamWatcher struct {
+	client.Reader
+	HNClient   hnapi.Client
+	HNStreamer hnapi.Streamer
+	// MinSyncInterval is how long after its last sync an HNews can be synced again,
+	// so that a busy feed doesn't keep the HNews which select it syncing all the time
+	MinSyncInterval time.Duration
+	// Clock is used to tell how long ago an HNews was synced
+	Clock clock.PassiveClock
+	// Events is where the HNews which need a sync are sent
+	Events chan<- event.GenericEvent
+}
+
+// Start streams all the feeds until ctx is done.
+// It implements manager.Runnable.
+func (w *streamWatcher) Start(ctx context.Context) error {
+	var wg sync.WaitGroup
+	for _, feed := range appsv1.AllFeeds {
+		wg.Add(1)
+		go func(feed appsv1.Feed) {
+			defer wg.Done()
+			w.watch(ctx, feed)
+		}(feed)
+	}
+	wg.Wait()
+	return nil
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable
+// as only the leader syncs HNews
+func (w *streamWatcher) NeedLeaderElection() bool {
+	return true
+}
+
+// feedWatch is the state of the watch of a single feed
+type feedWatch struct {
+	feed appsv1.Feed
+	// last is the last known (json) ids in the feed,
+	// "" if they aren't known
+	last string
+}
+
+// watch streams the feed and reconnects with a backoff when the stream breaks
+func (w *streamWatcher) watch(ctx context.Context, feed appsv1.Feed) {
+	fw := &feedWatch{feed: feed}
+	path := fmt.Sprintf("/%sstories.json", feed)
+	backoff := newStreamBackoff()
+
+	for {
+		log.Log.Info("streaming feed", "feed", feed)
+		received, err := w.stream(ctx, fw, path)
+		if ctx.Err() != nil {
+			return
+		}
+		if received {
+			backoff = newStreamBackoff()
+		}
+
+		retryIn := backoff.Step()
+		log.Log.Error(err, "feed stream broke, polling the feed until it's reconnected", "feed", feed, "retryIn", retryIn)
+		w.poll(ctx, fw, retryIn)
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// newStreamBackoff returns the backoff between the reconnects of a stream
+// from when it last received an event
+func newStreamBackoff() wait.Backoff {
+	return wait.Backoff{
+		Duration: streamRetryMin,
+		Factor:   2,
+		Jitter:   0.1,
+		Steps:    math.MaxInt32,
+		Cap:      streamRetryMax,
+	}
+}
+
+// stream streams the feed until the stream breaks
+// and tells if any event was received
+func (w *streamWatcher) stream(ctx context.Context, fw *feedWatch, path string) (bool, error) {
+	events := make(chan hnapi.Event)
+	errc := make(chan error, 1)
+	go func() {
+		errc <- w.HNStreamer.Stream(ctx, path, events)
+	}()
+
+	received := false
+	for {
+		select {
+		case e := <-events:
+			received = true
+			// a put on / has all the ids, anything else is a part of them
+			if e.Type == hnapi.EventPut && e.Path == "/" {
+				w.changed(ctx, fw, string(e.Data))
+			} else {
+				w.changed(ctx, fw, "")
+			}
+		case err := <-errc:
+			return received, err
+		}
+	}
+}
+
+// poll polls the feed every streamPollInterval for d
+func (w *streamWatcher) poll(ctx context.Context, fw *feedWatch, d time.Duration) {
+	ctx, cancel := context.WithTimeout(ctx, d)
+	defer cancel()
+
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		ids, err := w.HNClient.Feed(hnapi.WithPriority(ctx, hnapi.PriorityLow), hnapi.Feed(fw.feed+"stories"))
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Log.Error(err, "error polling feed", "feed", fw.feed)
+			}
+			return
+		}
+		data, err := json.Marshal(ids)
+		if err != nil {
+			return
+		}
+		w.changed(ctx, fw, string(data))
+	}, streamPollInterval)
+}
+
+// changed triggers a sync of the HNews which select the feed
+// if the ids in it aren't the last known ones
+func (w *streamWatcher) changed(ctx context.Context, fw *feedWatch, ids string) {
+	if ids != "" && ids == fw.last {
+		return
+	}
+	fw.last = ids
+
+	var hnList appsv1.HNewsList
+	if err := w.List(ctx, &hnList); err != nil {
+		log.Log.Error(err, "unable to list hnews")
+		return
+	}
+	for i := range hnList.Items {
+		hn := &hnList.Items[i]
+		if !selects(hn, fw.feed) || w.Clock.Since(hn.Status.LastSyncedAt.Time) < w.MinSyncInterval {
+			continue
+		}
+		select {
+		case w.Events <- event.GenericEvent{Object: hn}:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// selects tells if hn gets its Hacker News articles from the feed
+func selects(hn *appsv1.HNews, feed appsv1.Feed) bool {
+	for _, selected := range selectedFeeds(&hn.Spec) {
+		if selected == feed {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,162 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	testingclock "k8s.io/utils/clock/testing"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	appsv1 "github.com/vadasambar/hnews/api/v1"
+	"github.com/vadasambar/hnews/pkg/hnapi"
+)
+
+// fakeStreamer sends the events of its next stream and breaks it.
+// It counts the streams and blocks once it runs out of them.
+type fakeStreamer struct {
+	mu      sync.Mutex
+	streams [][]hnapi.Event
+	calls   int
+}
+
+func (s *fakeStreamer) Stream(ctx context.Context, path string, events chan<- hnapi.Event) error {
+	s.mu.Lock()
+	s.calls++
+	if len(s.streams) == 0 {
+		s.mu.Unlock()
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	stream := s.streams[0]
+	s.streams = s.streams[1:]
+	s.mu.Unlock()
+
+	for _, e := range stream {
+		select {
+		case events <- e:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return errors.New("stream broke")
+}
+
+func (s *fakeStreamer) Calls() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls
+}
+
+// fakeFeedClient serves the same ids for every feed
+type fakeFeedClient struct {
+	hnapi.Client
+	ids []int
+}
+
+func (c *fakeFeedClient) Feed(ctx context.Context, feed hnapi.Feed) ([]int, error) {
+	return c.ids, nil
+}
+
+// hnewsSyncedAgo returns an HNews which gets stories from the feed
+// and was last synced d before testNow
+func hnewsSyncedAgo(name string, feed appsv1.Feed, d time.Duration) *appsv1.HNews {
+	hn := &appsv1.HNews{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec:       appsv1.HNewsSpec{Feed: feed, Filter: appsv1.Filter{Type: string(appsv1.Story)}},
+	}
+	hn.Status.LastSyncedAt = metav1.NewTime(testNow.Add(-d))
+	return hn
+}
+
+func TestStreamWatcherChanged(t *testing.T) {
+	c := newFakeClient(t,
+		hnewsSyncedAgo("top", appsv1.TopFeed, time.Hour),
+		hnewsSyncedAgo("top-just-synced", appsv1.TopFeed, 10*time.Second),
+		hnewsSyncedAgo("show", appsv1.ShowFeed, time.Hour),
+	)
+	events := make(chan event.GenericEvent, 10)
+	w := &streamWatcher{Reader: c, MinSyncInterval: time.Minute, Clock: testingclock.NewFakePassiveClock(testNow), Events: events}
+	fw := &feedWatch{feed: appsv1.TopFeed}
+
+	w.changed(context.Background(), fw, "[1,2]")
+	if got, want := received(events), []string{"top"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("synced %v; want %v", got, want)
+	}
+	// the same ids aren't a change
+	w.changed(context.Background(), fw, "[1,2]")
+	if got := received(events); len(got) > 0 {
+		t.Errorf("synced %v; want none", got)
+	}
+	// a part of the ids always is
+	w.changed(context.Background(), fw, "")
+	if got, want := received(events), []string{"top"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("synced %v; want %v", got, want)
+	}
+}
+
+func TestStreamWatcherWatch(t *testing.T) {
+	c := newFakeClient(t, hnewsSyncedAgo("top", appsv1.TopFeed, time.Hour))
+	streamer := &fakeStreamer{streams: [][]hnapi.Event{
+		{{Type: hnapi.EventPut, Path: "/", Data: []byte("[1,2]")}},
+	}}
+	events := make(chan event.GenericEvent, 10)
+	w := &streamWatcher{
+		Reader:          c,
+		HNClient:        &fakeFeedClient{ids: []int{3, 1, 2}},
+		HNStreamer:      streamer,
+		MinSyncInterval: time.Minute,
+		Clock:           testingclock.NewFakePassiveClock(testNow),
+		Events:          events,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		w.watch(ctx, appsv1.TopFeed)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	// the put of the stream and then the poll once it broke
+	// (with different ids) both trigger a sync
+	for i := 0; i < 2; i++ {
+		select {
+		case e := <-events:
+			if e.Object.GetName() != "top" {
+				t.Errorf("synced %s; want top", e.Object.GetName())
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("got %d syncs; want 2", i)
+		}
+	}
+	// the stream is reconnected after the backoff
+	deadline := time.Now().Add(5 * time.Second)
+	for streamer.Calls() < 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("the stream wasn't reconnected")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func TestNewStreamBackoff(t *testing.T) {
+	backoff := newStreamBackoff()
+	want := streamRetryMin
+	for i := 0; i < 20; i++ {
+		got := backoff.Step()
+		if got < want || got > want+want/10 {
+			t.Errorf("step %d is %v; want %v with up to 10%% jitter", i, got, want)
+		}
+		if want *= 2; want > streamRetryMax {
+			want = streamRetryMax
+		}
+	}
+}
@@ -18,13 +18,20 @@ package controllers
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
+	testingclock "k8s.io/utils/clock/testing"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/envtest"
@@ -33,6 +40,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
 	appsv1 "github.com/vadasambar/hnews/api/v1"
+	"github.com/vadasambar/hnews/pkg/hnapi"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -45,8 +53,93 @@ var (
 	testEnv   *envtest.Environment
 	ctx       context.Context
 	cancel    context.CancelFunc
+	hnServer  *httptest.Server
 )
 
+// testNow is the time of the clock of the reconciler
+var testNow = time.Date(2022, 5, 26, 12, 0, 0, 0, time.UTC)
+
+// postedAgo returns the Unix time of an item posted d before testNow
+func postedAgo(d time.Duration) int {
+	return int(testNow.Add(-d).Unix())
+}
+
+// fakeItems are served by the fake Hacker News API
+// in the order of their rank in every feed
+var fakeItems = []appsv1.GetIdResponse{
+	{ID: 1, By: "alice", Type: appsv1.Story, Score: 500, Descendants: 120, Time: postedAgo(time.Hour), Title: "Show HN: A Kubernetes operator", URL: "https://github.com/vadasambar/hnews",
+		Kids: []int{101, 102, 103, 105}},
+	{ID: 2, By: "bob", Type: appsv1.Story, Score: 50, Descendants: 3, Time: postedAgo(5 * time.Hour), Title: "A small story", URL: "https://example.com/small"},
+	{ID: 3, By: "carol", Type: appsv1.Job, Score: 1, Time: postedAgo(2 * time.Hour), Title: "Hiring Go engineers"},
+	{ID: 4, By: "dave", Type: appsv1.Story, Score: 300, Descendants: 40, Time: postedAgo(30 * time.Hour), Title: "Ask HN: What are you working on?"},
+}
+
+// fakeComments are served by the fake Hacker News API but aren't in any feed
+var fakeComments = []appsv1.GetIdResponse{
+	{ID: 101, By: "eve", Type: appsv1.Comment, Parent: 1, Kids: []int{104}, Text: "Neat &amp; <i>tidy</i>.<p>Does it scale?"},
+	{ID: 102, Type: appsv1.Comment, Parent: 1, Deleted: true},
+	{ID: 103, By: "grace", Type: appsv1.Comment, Parent: 1, Text: "Nice"},
+	{ID: 104, By: "frank", Type: appsv1.Comment, Parent: 101, Text: "It does"},
+	{ID: 105, By: "mallory", Type: appsv1.Comment, Parent: 1, Dead: true, Text: "spam"},
+}
+
+// fakeFeeds are the feeds served by the fake Hacker News API
+// which don't return all of fakeItems
+var fakeFeeds = map[string][]int{
+	"/askstories.json": {4, 1},
+}
+
+// fakeUsers are the profiles of the authors of fakeItems
+// served by the fake Hacker News API
+var fakeUsers = []appsv1.GetUserResponse{
+	{ID: "alice", Karma: 5000, Created: postedAgo(5 * 365 * 24 * time.Hour)},
+	{ID: "bob", Karma: 200, Created: postedAgo(365 * 24 * time.Hour)},
+	{ID: "carol", Karma: 3000, Created: postedAgo(2 * 365 * 24 * time.Hour)},
+	{ID: "dave", Karma: 1, Created: postedAgo(40 * time.Hour)},
+}
+
+// newFakeHNServer returns a server which serves fakeItems,
+// fakeComments and fakeUsers like the Hacker News API does
+func newFakeHNServer() *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		var id int
+		if _, err := fmt.Sscanf(r.URL.Path, "/item/%d.json", &id); err == nil {
+			for _, item := range append(fakeItems, fakeComments...) {
+				if item.ID == id {
+					_ = json.NewEncoder(w).Encode(item)
+					return
+				}
+			}
+			_, _ = w.Write([]byte("null"))
+			return
+		}
+		if strings.HasPrefix(r.URL.Path, "/user/") {
+			id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/user/"), ".json")
+			for _, user := range fakeUsers {
+				if user.ID == id {
+					_ = json.NewEncoder(w).Encode(user)
+					return
+				}
+			}
+			_, _ = w.Write([]byte("null"))
+			return
+		}
+
+		if ids, ok := fakeFeeds[r.URL.Path]; ok {
+			_ = json.NewEncoder(w).Encode(ids)
+			return
+		}
+		// every other feed returns all the items
+		ids := []int{}
+		for _, item := range fakeItems {
+			ids = append(ids, item.ID)
+		}
+		_ = json.NewEncoder(w).Encode(ids)
+	})
+	return httptest.NewServer(mux)
+}
+
 func TestAPIs(t *testing.T) {
 	RegisterFailHandler(Fail)
 
@@ -84,9 +177,13 @@ var _ = BeforeSuite(func() {
 	})
 	Expect(err).NotTo(HaveOccurred())
 
+	hnServer = newFakeHNServer()
+
 	err = (&HNewsReconciler{
-		Client: k8sManager.GetClient(),
-		Scheme: k8sManager.GetScheme(),
+		Client:   k8sManager.GetClient(),
+		Scheme:   k8sManager.GetScheme(),
+		HNClient: hnapi.New(hnapi.Options{BaseURL: hnServer.URL}),
+		Clock:    testingclock.NewFakePassiveClock(testNow),
 	}).SetupWithManager(k8sManager)
 	Expect(err).NotTo(HaveOccurred())
 
@@ -100,6 +197,9 @@ var _ = BeforeSuite(func() {
 
 var _ = AfterSuite(func() {
 	cancel()
+	if hnServer != nil {
+		hnServer.Close()
+	}
 	By("tearing down the test environment")
 	err := testEnv.Stop()
 	Expect(err).NotTo(HaveOccurred())
@@ -0,0 +1,201 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	appsv1 "github.com/vadasambar/hnews/api/v1"
+	"github.com/vadasambar/hnews/pkg/hnapi"
+)
+
+const (
+	// defaultThreadDepth, defaultThreadItems and defaultThreadTimeout
+	// are used for the fields of `spec.threads` which aren't set
+	defaultThreadDepth   = 3
+	defaultThreadItems   = 1000
+	defaultThreadTimeout = 30 * time.Second
+)
+
+// thread is where a comment was found
+type thread struct {
+	// story is the story (or poll) the comment is in
+	story *appsv1.GetIdResponse
+	// depth is 1 for the top level comments, 2 for the replies to them and so on
+	depth int
+}
+
+// threadWalk walks the discussion threads of stories
+// for the comments which match the filter of an HNews
+type threadWalk struct {
+	hnClient    hnapi.Client
+	concurrency int
+	// accept decides if a comment is a part of the HNews
+	accept func(ctx context.Context, item *appsv1.GetIdResponse) (bool, error)
+	// skip decides if an item which couldn't be fetched or accepted
+	// is skipped (true) or the walk stops with the error (false)
+	skip func(id int, err error) bool
+	// maxDepth is the number of levels of comments walked
+	maxDepth int
+	// budget is the number of items which can still be fetched
+	budget int
+	// limit is the number of comments after which the walk stops (0 for no limit)
+	limit int
+
+	results []hnapi.Result
+	// threads has where every comment in results was found (id => thread)
+	threads map[int]thread
+}
+
+// walkThreads walks the threads of the stories with storyIDs, in order and within
+// the bounds of spec, and returns the comments accept accepts in the order they were
+// found, along with where they were found. The comments found so far are returned
+// when the walk runs out of items or time.
+func (r *HNewsReconciler) walkThreads(ctx context.Context, storyIDs []int, spec *appsv1.ThreadsSpec, limit int,
+	accept func(ctx context.Context, item *appsv1.GetIdResponse) (bool, error),
+	skip func(id int, err error) bool) ([]hnapi.Result, map[int]thread, error) {
+	w := &threadWalk{
+		hnClient:    r.HNClient,
+		concurrency: r.FetchConcurrency,
+		accept:      accept,
+		skip:        skip,
+		maxDepth:    defaultThreadDepth,
+		budget:      defaultThreadItems,
+		limit:       limit,
+		threads:     map[int]thread{},
+	}
+	timeout := defaultThreadTimeout
+	if spec != nil {
+		if spec.Depth > 0 {
+			w.maxDepth = spec.Depth
+		}
+		if spec.MaxItems > 0 {
+			w.budget = spec.MaxItems
+		}
+		if spec.Timeout != nil && spec.Timeout.Duration > 0 {
+			timeout = spec.Timeout.Duration
+		}
+	}
+
+	walkCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	if err := w.walkStories(walkCtx, storyIDs); err != nil {
+		return nil, nil, err
+	}
+	if ctx.Err() != nil {
+		return nil, nil, ctx.Err()
+	}
+	if walkCtx.Err() != nil {
+		log.Log.Info("stopped walking the threads at the timeout", "timeout", timeout, "comments", len(w.results))
+	} else if w.budget == 0 {
+		log.Log.Info("stopped walking the threads at the maximum number of items", "comments", len(w.results))
+	}
+	return w.results, w.threads, nil
+}
+
+// done tells if the walk found as many comments as it needs
+func (w *threadWalk) done() bool {
+	return w.limit > 0 && len(w.results) >= w.limit
+}
+
+// walkStories walks the threads of the stories with ids
+func (w *threadWalk) walkStories(ctx context.Context, ids []int) error {
+	// the stories are fetched a few at a time so that
+	// the budget isn't spent on stories which aren't walked
+	batch := w.concurrency
+	if batch <= 0 {
+		batch = hnapi.DefaultConcurrency
+	}
+	for start := 0; start < len(ids) && w.budget > 0 && !w.done(); start += batch {
+		end := start + batch
+		if end > len(ids) {
+			end = len(ids)
+		}
+		stories, err := w.fetch(ctx, ids[start:end])
+		if err != nil {
+			return err
+		}
+		for _, story := range stories {
+			if err := w.walkReplies(ctx, story.Item, story.Item.Kids, 1); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// walkReplies walks the comments with ids at depth of the thread of story
+// and then their replies, so that the replies to a comment come right after it
+func (w *threadWalk) walkReplies(ctx context.Context, story *appsv1.GetIdResponse, ids []int, depth int) error {
+	if depth > w.maxDepth || len(ids) == 0 || w.done() {
+		return nil
+	}
+	comments, err := w.fetch(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for _, comment := range comments {
+		if w.done() {
+			return nil
+		}
+		item := comment.Item
+		if item.Type == appsv1.Comment && !item.Deleted && !item.Dead {
+			accepted, err := w.accept(ctx, item)
+			switch {
+			case err != nil && ctx.Err() != nil:
+				// the walk timed out while e.g., the profile of the author was fetched
+			case err != nil:
+				if !w.skip(item.ID, err) {
+					return err
+				}
+			case accepted:
+				w.results = append(w.results, hnapi.Result{Rank: len(w.results), Item: item})
+				w.threads[item.ID] = thread{story: story, depth: depth}
+			}
+		}
+		// the replies to deleted and dead comments are still in the thread
+		if err := w.walkReplies(ctx, story, item.Kids, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fetch fetches the items with ids, or as many of them as the budget allows.
+// Nothing is fetched once the walk timed out and the items which weren't
+// fetched in time are skipped, so that the ones which were are kept.
+func (w *threadWalk) fetch(ctx context.Context, ids []int) ([]hnapi.Result, error) {
+	if len(ids) > w.budget {
+		ids = ids[:w.budget]
+	}
+	if len(ids) == 0 || ctx.Err() != nil {
+		return nil, nil
+	}
+	w.budget -= len(ids)
+	return hnapi.FetchItems(ctx, w.hnClient, ids, hnapi.FetchOptions{
+		Concurrency: w.concurrency,
+		OnError: func(rank int, err error) bool {
+			if ctx.Err() != nil {
+				return true
+			}
+			return w.skip(ids[rank], err)
+		},
+	})
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/utils/clock"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	appsv1 "github.com/vadasambar/hnews/api/v1"
+	"github.com/vadasambar/hnews/pkg/hnapi"
+)
+
+// updatesWatcher polls /updates.json for the items which changed recently.
+// It invalidates them in the item cache and triggers a sync of the HNews
+// which have them in their status or their result pages, so that only
+// the items which changed are fetched again and scores in the status are fresh.
+type updatesWatcher struct {
+	client.Reader
+	HNClient hnapi.Client
+	Interval time.Duration
+	// MinSyncInterval is how long after its last sync an HNews can be synced again,
+	// so that items which keep changing don't keep the HNews syncing all the time
+	MinSyncInterval time.Duration
+	// Clock is used to tell how long ago an HNews was synced
+	Clock clock.PassiveClock
+	// Events is where the HNews which need a sync are sent
+	Events chan<- event.GenericEvent
+}
+
+// Start polls /updates.json every Interval until ctx is done.
+// It implements manager.Runnable.
+func (w *updatesWatcher) Start(ctx context.Context) error {
+	log.Log.Info("polling /updates.json", "interval", w.Interval)
+	wait.UntilWithContext(ctx, w.poll, w.Interval)
+	return nil
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable
+// as only the leader syncs HNews
+func (w *updatesWatcher) NeedLeaderElection() bool {
+	return true
+}
+
+func (w *updatesWatcher) poll(ctx context.Context) {
+	updates, err := w.HNClient.Updates(hnapi.WithPriority(ctx, hnapi.PriorityLow))
+	if err != nil {
+		log.Log.Error(err, "error getting /updates.json from the API")
+		return
+	}
+	if cache, ok := w.HNClient.(hnapi.Invalidator); ok {
+		cache.Invalidate(updates.Items...)
+		cache.InvalidateUsers(updates.Profiles...)
+	}
+	if len(updates.Items) == 0 {
+		return
+	}
+
+	changed := map[int]bool{}
+	for _, id := range updates.Items {
+		changed[id] = true
+	}
+
+	var hnList appsv1.HNewsList
+	if err := w.List(ctx, &hnList); err != nil {
+		log.Log.Error(err, "unable to list hnews")
+		return
+	}
+	// the links which don't fit in the status of an HNews are in its result pages
+	var results appsv1.HNewsResultList
+	if err := w.List(ctx, &results); err != nil {
+		log.Log.Error(err, "unable to list hnews results")
+		return
+	}
+	changedResults := map[types.NamespacedName]bool{}
+	for i := range results.Items {
+		result := &results.Items[i]
+		if hasChangedLink(result.Links, changed) {
+			changedResults[types.NamespacedName{Namespace: result.Namespace, Name: result.HNews}] = true
+		}
+	}
+
+	for i := range hnList.Items {
+		hn := &hnList.Items[i]
+		if !hasChangedLink(hn.Status.Links, changed) && !changedResults[client.ObjectKeyFromObject(hn)] {
+			continue
+		}
+		if w.Clock.Since(hn.Status.LastSyncedAt.Time) < w.MinSyncInterval {
+			continue
+		}
+		select {
+		case w.Events <- event.GenericEvent{Object: hn}:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// hasChangedLink tells if any of the links is for a changed item
+func hasChangedLink(links []appsv1.Link, changed map[int]bool) bool {
+	for _, link := range links {
+		if changed[link.ID] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,104 @@
+package controllers
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	testingclock "k8s.io/utils/clock/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	appsv1 "github.com/vadasambar/hnews/api/v1"
+	"github.com/vadasambar/hnews/pkg/hnapi"
+)
+
+// fakeUpdatesClient serves /updates.json and records what's invalidated
+type fakeUpdatesClient struct {
+	hnapi.Client
+	updates          *appsv1.GetUpdatesResponse
+	invalidated      []int
+	invalidatedUsers []string
+}
+
+func (c *fakeUpdatesClient) Updates(ctx context.Context) (*appsv1.GetUpdatesResponse, error) {
+	return c.updates, nil
+}
+
+func (c *fakeUpdatesClient) Invalidate(ids ...int) {
+	c.invalidated = append(c.invalidated, ids...)
+}
+
+func (c *fakeUpdatesClient) InvalidateUsers(ids ...string) {
+	c.invalidatedUsers = append(c.invalidatedUsers, ids...)
+}
+
+// newFakeClient returns a fake client with the HNews API and objs
+func newFakeClient(t *testing.T, objs ...client.Object) client.Client {
+	s := runtime.NewScheme()
+	if err := appsv1.AddToScheme(s); err != nil {
+		t.Fatal(err)
+	}
+	return fake.NewClientBuilder().WithScheme(s).WithObjects(objs...).Build()
+}
+
+// hnewsWithLinks returns an HNews with links for the items with ids in its status
+func hnewsWithLinks(name string, ids ...int) *appsv1.HNews {
+	hn := &appsv1.HNews{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}}
+	for _, id := range ids {
+		hn.Status.Links = append(hn.Status.Links, appsv1.Link{ID: id})
+	}
+	return hn
+}
+
+// received returns the names of the objects of the events sent to events so far
+func received(events chan event.GenericEvent) []string {
+	names := []string{}
+	for {
+		select {
+		case e := <-events:
+			names = append(names, e.Object.GetName())
+		default:
+			sort.Strings(names)
+			return names
+		}
+	}
+}
+
+func TestUpdatesWatcherPoll(t *testing.T) {
+	page := &appsv1.HNewsResult{
+		ObjectMeta: metav1.ObjectMeta{Name: "in-pages-0", Namespace: "default"},
+		HNews:      "in-pages",
+		Links:      []appsv1.Link{{ID: 5}},
+	}
+	justSynced := hnewsWithLinks("just-synced", 1)
+	justSynced.Status.LastSyncedAt = metav1.NewTime(testNow.Add(-10 * time.Second))
+	c := newFakeClient(t, hnewsWithLinks("in-status", 1, 2), hnewsWithLinks("in-pages", 3), hnewsWithLinks("unchanged", 4), justSynced, page)
+	hnClient := &fakeUpdatesClient{updates: &appsv1.GetUpdatesResponse{Items: []int{5, 1}, Profiles: []string{"alice"}}}
+	events := make(chan event.GenericEvent, 10)
+	w := &updatesWatcher{Reader: c, HNClient: hnClient, MinSyncInterval: time.Minute, Clock: testingclock.NewFakePassiveClock(testNow), Events: events}
+
+	w.poll(context.Background())
+	if want := []int{5, 1}; !reflect.DeepEqual(hnClient.invalidated, want) {
+		t.Errorf("invalidated items %v; want %v", hnClient.invalidated, want)
+	}
+	if want := []string{"alice"}; !reflect.DeepEqual(hnClient.invalidatedUsers, want) {
+		t.Errorf("invalidated users %v; want %v", hnClient.invalidatedUsers, want)
+	}
+	// the HNews synced less than MinSyncInterval ago isn't synced again
+	if got, want := received(events), []string{"in-pages", "in-status"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("synced %v; want %v", got, want)
+	}
+
+	// nothing is synced when nothing changed
+	hnClient.updates = &appsv1.GetUpdatesResponse{Items: []int{42}}
+	w.poll(context.Background())
+	if got := received(events); len(got) > 0 {
+		t.Errorf("synced %v; want none", got)
+	}
+}
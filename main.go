@@ -19,6 +19,7 @@ package main
 import (
 	"flag"
 	"os"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -26,6 +27,7 @@ import (
 
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
@@ -33,6 +35,8 @@ import (
 
 	appsv1 "github.com/vadasambar/hnews/api/v1"
 	"github.com/vadasambar/hnews/controllers"
+	"github.com/vadasambar/hnews/pkg/hnapi"
+	"github.com/vadasambar/hnews/webhooks"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -52,11 +56,73 @@ func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var hnAPIBaseURL string
+	var hnAPITimeout time.Duration
+	var hnAPIUserAgent string
+	var hnAPIRetry hnapi.RetryOptions
+	var hnAPIBreaker hnapi.BreakerOptions
+	var hnAPIRateLimit hnapi.RateLimitOptions
+	var fetchConcurrency int
+	var cacheOpts hnapi.CacheOptions
+	var defaultSyncInterval time.Duration
+	var minSyncInterval time.Duration
+	var updatesInterval time.Duration
+	var stream bool
+	var defaultType, defaultScore, defaultDescendants string
+	var defaults appsv1.Defaults
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.StringVar(&hnAPIBaseURL, "hn-api-base-url", hnapi.DefaultBaseURL,
+		"The base URL of the Hacker News API. Point it at a mirror or a caching proxy to avoid talking to the public API.")
+	flag.DurationVar(&hnAPITimeout, "hn-api-timeout", hnapi.DefaultTimeout, "The timeout for a single Hacker News API request.")
+	flag.StringVar(&hnAPIUserAgent, "hn-api-user-agent", hnapi.DefaultUserAgent, "The User-Agent sent with Hacker News API requests.")
+	flag.IntVar(&hnAPIRetry.MaxRetries, "hn-api-max-retries", hnapi.DefaultMaxRetries,
+		"The number of times a failed Hacker News API request is retried, with an exponential backoff. -1 disables retries.")
+	flag.IntVar(&hnAPIBreaker.Threshold, "hn-api-breaker-threshold", hnapi.DefaultBreakerThreshold,
+		"The number of Hacker News API requests in a row which have to fail for all requests to be paused. -1 disables it.")
+	flag.DurationVar(&hnAPIBreaker.Cooldown, "hn-api-breaker-cooldown", hnapi.DefaultBreakerCooldown,
+		"How long Hacker News API requests are paused for when the API keeps failing.")
+	flag.Float64Var(&hnAPIRateLimit.QPS, "hn-api-qps", hnapi.DefaultQPS,
+		"The number of Hacker News API requests per second allowed for the whole controller. -1 disables rate limiting.")
+	flag.IntVar(&hnAPIRateLimit.Burst, "hn-api-burst", hnapi.DefaultBurst,
+		"The number of Hacker News API requests which can be made at once for the whole controller.")
+	flag.Float64Var(&hnAPIRateLimit.NamespaceQPS, "hn-api-namespace-qps", 0,
+		"The number of Hacker News API requests per second allowed for the HNews resources of a namespace. "+
+			"Namespaces don't have a budget of their own if it's 0.")
+	flag.IntVar(&hnAPIRateLimit.NamespaceBurst, "hn-api-namespace-burst", 0,
+		"The number of Hacker News API requests which can be made at once for the HNews resources of a namespace.")
+	flag.IntVar(&fetchConcurrency, "hn-fetch-concurrency", hnapi.DefaultConcurrency,
+		"The maximum number of items a single reconcile fetches from the Hacker News API at the same time.")
+	flag.IntVar(&cacheOpts.Size, "hn-cache-size", hnapi.DefaultCacheSize,
+		"The maximum number of Hacker News items in the cache shared by all the HNews resources.")
+	flag.DurationVar(&cacheOpts.HotTTL, "hn-cache-hot-ttl", hnapi.DefaultHotTTL, "How long hot items are cached for.")
+	flag.DurationVar(&cacheOpts.OldTTL, "hn-cache-old-ttl", hnapi.DefaultOldTTL, "How long old items are cached for.")
+	flag.DurationVar(&cacheOpts.HotAge, "hn-cache-hot-age", hnapi.DefaultHotAge, "The age after which an item isn't hot anymore.")
+	flag.IntVar(&cacheOpts.UserSize, "hn-cache-user-size", hnapi.DefaultUserCacheSize,
+		"The maximum number of Hacker News user profiles in the cache shared by all the HNews resources.")
+	flag.DurationVar(&cacheOpts.UserTTL, "hn-cache-user-ttl", hnapi.DefaultUserTTL, "How long user profiles are cached for.")
+	flag.DurationVar(&defaultSyncInterval, "default-sync-interval", controllers.DefaultSyncInterval,
+		"How often HNews resources without spec.syncInterval are synced.")
+	flag.DurationVar(&minSyncInterval, "min-sync-interval", controllers.MinSyncInterval,
+		"The lowest spec.syncInterval that is honored. Lower intervals are raised to it.")
+	flag.DurationVar(&updatesInterval, "hn-updates-interval", 0,
+		"How often /updates.json is polled to sync the HNews resources whose items changed. "+
+			"The changed items are removed from the cache, so --hn-cache-hot-ttl can be raised when it's enabled. "+
+			"It isn't polled if it's 0.")
+	flag.BoolVar(&stream, "hn-stream", false,
+		"Keep a stream open to every Hacker News feed and sync the HNews resources as soon as their feeds change. "+
+			"A feed is polled while its stream is down.")
+	flag.StringVar(&defaultType, "default-type", string(appsv1.BuiltinDefaults.Type),
+		"The spec.filter.type of HNews resources which don't set it.")
+	flag.IntVar(&defaults.Limit, "default-limit", appsv1.BuiltinDefaults.Limit,
+		"The spec.filter.limit of HNews resources which don't set it.")
+	flag.StringVar(&defaultScore, "default-score", string(appsv1.BuiltinDefaults.Score),
+		"The spec.filter.score of HNews resources which don't set it.")
+	flag.StringVar(&defaultDescendants, "default-descendents", string(appsv1.BuiltinDefaults.Descendants),
+		"The spec.filter.descendents of HNews resources which don't set it.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -65,6 +131,19 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	defaults.Type = appsv1.Type(defaultType)
+	defaults.Score = appsv1.Comparison(defaultScore)
+	defaults.Descendants = appsv1.Comparison(defaultDescendants)
+	if errs := webhooks.ValidateSpec(&appsv1.HNewsSpec{Filter: appsv1.Filter{
+		Type:        defaultType,
+		Limit:       defaults.Limit,
+		Score:       defaults.Score,
+		Descendants: defaults.Descendants,
+	}}, field.NewPath("default")); len(errs) > 0 {
+		setupLog.Error(errs.ToAggregate(), "invalid defaults")
+		os.Exit(1)
+	}
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:                 scheme,
 		MetricsBindAddress:     metricsAddr,
@@ -78,13 +157,43 @@ func main() {
 		os.Exit(1)
 	}
 
+	hnAPIOpts := hnapi.Options{
+		BaseURL:   hnAPIBaseURL,
+		Timeout:   hnAPITimeout,
+		UserAgent: hnAPIUserAgent,
+		Retry:     hnAPIRetry,
+		Breaker:   hnAPIBreaker,
+		RateLimit: hnAPIRateLimit,
+	}
+	var hnStreamer hnapi.Streamer
+	if stream {
+		hnStreamer = hnapi.NewStreamer(hnAPIOpts)
+	}
+
 	if err = (&controllers.HNewsReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:              mgr.GetClient(),
+		Scheme:              mgr.GetScheme(),
+		HNClient:            hnapi.NewCachingClient(hnapi.New(hnAPIOpts), cacheOpts),
+		HNStreamer:          hnStreamer,
+		Defaults:            defaults,
+		FetchConcurrency:    fetchConcurrency,
+		DefaultSyncInterval: defaultSyncInterval,
+		MinSyncInterval:     minSyncInterval,
+		UpdatesInterval:     updatesInterval,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "HNews")
 		os.Exit(1)
 	}
+	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		if err = (&webhooks.HNewsDefaulter{Defaults: defaults}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "HNews")
+			os.Exit(1)
+		}
+		if err = (&webhooks.HNewsValidator{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "HNews")
+			os.Exit(1)
+		}
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -0,0 +1,114 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package author matches the authors of Hacker News items
+// against the author filter of an HNews
+package author
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	appsv1 "github.com/vadasambar/hnews/api/v1"
+	"github.com/vadasambar/hnews/pkg/comparison"
+)
+
+// usernameRegexp is what Hacker News allows in a username
+var usernameRegexp = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Matcher matches authors against an author filter
+type Matcher struct {
+	include map[string]bool
+	exclude map[string]bool
+	// karma is nil if the filter doesn't set it
+	karma  *comparison.Comparison
+	minAge time.Duration
+}
+
+// Compile parses the author filter
+func Compile(filter *appsv1.AuthorFilter) (*Matcher, error) {
+	m := &Matcher{}
+	if filter == nil {
+		return m, nil
+	}
+
+	for i, name := range filter.Include {
+		if err := Validate(name); err != nil {
+			return nil, fmt.Errorf("include[%d]: %w", i, err)
+		}
+		if m.include == nil {
+			m.include = map[string]bool{}
+		}
+		m.include[name] = true
+	}
+	for i, name := range filter.Exclude {
+		if err := Validate(name); err != nil {
+			return nil, fmt.Errorf("exclude[%d]: %w", i, err)
+		}
+		if m.exclude == nil {
+			m.exclude = map[string]bool{}
+		}
+		m.exclude[name] = true
+	}
+	if filter.Karma != "" {
+		karma, err := comparison.Parse(string(filter.Karma))
+		if err != nil {
+			return nil, fmt.Errorf("karma: %w", err)
+		}
+		m.karma = &karma
+	}
+	if filter.MinAccountAge != nil {
+		if filter.MinAccountAge.Duration < 0 {
+			return nil, fmt.Errorf("minAccountAge: can't be negative")
+		}
+		m.minAge = filter.MinAccountAge.Duration
+	}
+	return m, nil
+}
+
+// Validate returns an error if the username can't be in an author filter
+func Validate(name string) error {
+	if !usernameRegexp.MatchString(name) {
+		return fmt.Errorf("invalid username %q: has to consist of letters, digits, '-' and '_'", name)
+	}
+	return nil
+}
+
+// Matches tells if the author is one of the included ones (if there are any)
+// and none of the excluded ones
+func (m *Matcher) Matches(name string) bool {
+	if m.exclude[name] {
+		return false
+	}
+	return m.include == nil || m.include[name]
+}
+
+// NeedsProfile tells if the profile of the author
+// has to be fetched for MatchesProfile
+func (m *Matcher) NeedsProfile() bool {
+	return m.karma != nil || m.minAge > 0
+}
+
+// MatchesProfile tells if the karma and the age of the account
+// of the author (at now) match the filter
+func (m *Matcher) MatchesProfile(user *appsv1.GetUserResponse, now time.Time) bool {
+	if m.karma != nil && !m.karma.Matches(user.Karma) {
+		return false
+	}
+	created := time.Unix(int64(user.Created), 0)
+	return now.Sub(created) >= m.minAge
+}
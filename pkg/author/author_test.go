@@ -0,0 +1,89 @@
+package author
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	appsv1 "github.com/vadasambar/hnews/api/v1"
+)
+
+func TestMatches(t *testing.T) {
+	tests := []struct {
+		filter *appsv1.AuthorFilter
+		name   string
+		want   bool
+	}{
+		{nil, "alice", true},
+		{&appsv1.AuthorFilter{Include: []string{"alice", "bob"}}, "bob", true},
+		{&appsv1.AuthorFilter{Include: []string{"alice", "bob"}}, "carol", false},
+		{&appsv1.AuthorFilter{Include: []string{"alice"}}, "Alice", false},
+		{&appsv1.AuthorFilter{Exclude: []string{"spammer"}}, "spammer", false},
+		{&appsv1.AuthorFilter{Exclude: []string{"spammer"}}, "alice", true},
+		{&appsv1.AuthorFilter{Include: []string{"alice"}, Exclude: []string{"alice"}}, "alice", false},
+	}
+	for _, test := range tests {
+		m, err := Compile(test.filter)
+		if err != nil {
+			t.Errorf("Compile(%+v) returned error %v", test.filter, err)
+			continue
+		}
+		if got := m.Matches(test.name); got != test.want {
+			t.Errorf("Matches(%q) with %+v = %v; want %v", test.name, test.filter, got, test.want)
+		}
+	}
+}
+
+func TestMatchesProfile(t *testing.T) {
+	now := time.Unix(1650000000, 0)
+	month := &metav1.Duration{Duration: 30 * 24 * time.Hour}
+	old := &appsv1.GetUserResponse{ID: "old", Karma: 5000, Created: int(now.Add(-365 * 24 * time.Hour).Unix())}
+	fresh := &appsv1.GetUserResponse{ID: "fresh", Karma: 1, Created: int(now.Add(-time.Hour).Unix())}
+
+	tests := []struct {
+		filter    *appsv1.AuthorFilter
+		user      *appsv1.GetUserResponse
+		want      bool
+		wantFetch bool
+	}{
+		{&appsv1.AuthorFilter{}, fresh, true, false},
+		{&appsv1.AuthorFilter{Karma: ">=1000"}, old, true, true},
+		{&appsv1.AuthorFilter{Karma: ">=1000"}, fresh, false, true},
+		{&appsv1.AuthorFilter{MinAccountAge: month}, old, true, true},
+		{&appsv1.AuthorFilter{MinAccountAge: month}, fresh, false, true},
+		{&appsv1.AuthorFilter{Karma: "<10", MinAccountAge: month}, fresh, false, true},
+	}
+	for _, test := range tests {
+		m, err := Compile(test.filter)
+		if err != nil {
+			t.Errorf("Compile(%+v) returned error %v", test.filter, err)
+			continue
+		}
+		if got := m.NeedsProfile(); got != test.wantFetch {
+			t.Errorf("NeedsProfile() with %+v = %v; want %v", test.filter, got, test.wantFetch)
+		}
+		if got := m.MatchesProfile(test.user, now); got != test.want {
+			t.Errorf("MatchesProfile(%q) with %+v = %v; want %v", test.user.ID, test.filter, got, test.want)
+		}
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		filter *appsv1.AuthorFilter
+		want   string
+	}{
+		{&appsv1.AuthorFilter{Include: []string{"alice", ""}}, "include[1]: invalid username"},
+		{&appsv1.AuthorFilter{Exclude: []string{"no spaces"}}, "exclude[0]: invalid username"},
+		{&appsv1.AuthorFilter{Karma: "lots"}, "karma: invalid comparison"},
+		{&appsv1.AuthorFilter{MinAccountAge: &metav1.Duration{Duration: -time.Hour}}, "minAccountAge: can't be negative"},
+	}
+	for _, test := range tests {
+		_, err := Compile(test.filter)
+		if err == nil || !strings.HasPrefix(err.Error(), test.want) {
+			t.Errorf("Compile(%+v) returned error %v; want %q...", test.filter, err, test.want)
+		}
+	}
+}
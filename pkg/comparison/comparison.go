@@ -0,0 +1,254 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package comparison parses and evaluates the comparisons
+// in the filter of an HNews e.g., ">=10", ">=10,<100" or "between 10 and 100"
+package comparison
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Op is a comparison operator
+type Op string
+
+const (
+	GreaterThan        Op = ">"
+	GreaterThanOrEqual Op = ">="
+	LessThan           Op = "<"
+	LessThanOrEqual    Op = "<="
+	Equal              Op = "="
+	NotEqual           Op = "!="
+)
+
+// Term compares a value with a number e.g., ">=10"
+type Term struct {
+	Op    Op
+	Value int
+}
+
+// Matches tells if the value satisfies the term
+func (t Term) Matches(value int) bool {
+	switch t.Op {
+	case GreaterThan:
+		return value > t.Value
+	case GreaterThanOrEqual:
+		return value >= t.Value
+	case LessThan:
+		return value < t.Value
+	case LessThanOrEqual:
+		return value <= t.Value
+	case Equal:
+		return value == t.Value
+	case NotEqual:
+		return value != t.Value
+	}
+	return false
+}
+
+func (t Term) String() string {
+	return fmt.Sprintf("%s%d", t.Op, t.Value)
+}
+
+// Comparison is a list of terms which all have to match
+// e.g., ">=10,<100" is [>=10 <100]
+type Comparison struct {
+	Terms []Term
+}
+
+// Matches tells if the value satisfies all the terms
+func (c Comparison) Matches(value int) bool {
+	for _, term := range c.Terms {
+		if !term.Matches(value) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c Comparison) String() string {
+	terms := make([]string, len(c.Terms))
+	for i, term := range c.Terms {
+		terms[i] = term.String()
+	}
+	return strings.Join(terms, ",")
+}
+
+// SyntaxError is returned when a comparison can't be parsed
+type SyntaxError struct {
+	// Input is the comparison which couldn't be parsed
+	Input string
+	// Offset is where in Input the error is
+	Offset int
+	Msg    string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("invalid comparison %q at offset %d: %s", e.Input, e.Offset, e.Msg)
+}
+
+// Parse parses a comparison. It is a comma separated list of terms
+// which all have to match. A term is one of:
+//   - an operator (>, >=, <, <=, = or !=) followed by a number e.g., ">=10"
+//   - "between <number> and <number>" (inclusive) e.g., "between 10 and 100"
+//
+// Numbers can be negative e.g., ">= -5"
+func Parse(s string) (Comparison, error) {
+	p := &parser{input: s}
+	if p.skipSpace(); p.eof() {
+		return Comparison{}, p.errorf("it's empty, expected something like \">=10\"")
+	}
+
+	var c Comparison
+	for {
+		terms, err := p.term()
+		if err != nil {
+			return Comparison{}, err
+		}
+		c.Terms = append(c.Terms, terms...)
+
+		p.skipSpace()
+		if p.eof() {
+			return c, nil
+		}
+		if !p.consume(",") {
+			return Comparison{}, p.errorf("expected \",\" or the end, got %q", p.rest())
+		}
+	}
+}
+
+// ops are the operators the parser looks for, longest first
+var ops = []Op{GreaterThanOrEqual, LessThanOrEqual, NotEqual, GreaterThan, LessThan, Equal}
+
+type parser struct {
+	input string
+	pos   int
+}
+
+// term parses a single term, which is two terms for "between"
+func (p *parser) term() ([]Term, error) {
+	p.skipSpace()
+	for _, op := range ops {
+		if p.consume(string(op)) {
+			value, err := p.number()
+			if err != nil {
+				return nil, err
+			}
+			return []Term{{Op: op, Value: value}}, nil
+		}
+	}
+
+	if p.keyword("between") {
+		start := p.pos
+		min, err := p.number()
+		if err != nil {
+			return nil, err
+		}
+		if !p.keyword("and") {
+			return nil, p.errorf("expected \"and\" after \"between %d\", got %q", min, p.rest())
+		}
+		max, err := p.number()
+		if err != nil {
+			return nil, err
+		}
+		if min > max {
+			p.pos = start
+			return nil, p.errorf("%d is greater than %d in \"between %d and %d\"", min, max, min, max)
+		}
+		return []Term{{Op: GreaterThanOrEqual, Value: min}, {Op: LessThanOrEqual, Value: max}}, nil
+	}
+
+	if p.eof() || p.peek() == ',' {
+		return nil, p.errorf("expected a term like \">=10\" or \"between 10 and 100\"")
+	}
+	return nil, p.errorf("expected an operator (one of >, >=, <, <=, =, !=) or \"between\", got %q", p.rest())
+}
+
+// number parses an integer which can have a sign
+func (p *parser) number() (int, error) {
+	p.skipSpace()
+	start := p.pos
+	if !p.eof() && (p.peek() == '-' || p.peek() == '+') {
+		p.pos++
+	}
+	p.skipSpace()
+	digits := p.pos
+	for !p.eof() && p.peek() >= '0' && p.peek() <= '9' {
+		p.pos++
+	}
+	if p.pos == digits {
+		return 0, p.errorf("expected a number, got %q", p.rest())
+	}
+
+	literal := strings.Join(strings.Fields(p.input[start:p.pos]), "")
+	value, err := strconv.Atoi(literal)
+	if err != nil {
+		p.pos = start
+		return 0, p.errorf("number %s is out of range", literal)
+	}
+	return value, nil
+}
+
+// keyword consumes the word if it's next (case insensitive)
+func (p *parser) keyword(word string) bool {
+	p.skipSpace()
+	end := p.pos + len(word)
+	if end > len(p.input) || !strings.EqualFold(p.input[p.pos:end], word) {
+		return false
+	}
+	// "betweenx" isn't "between"
+	if end < len(p.input) && isLetter(p.input[end]) {
+		return false
+	}
+	p.pos = end
+	return true
+}
+
+func (p *parser) consume(s string) bool {
+	if strings.HasPrefix(p.input[p.pos:], s) {
+		p.pos += len(s)
+		return true
+	}
+	return false
+}
+
+func (p *parser) skipSpace() {
+	for !p.eof() && (p.peek() == ' ' || p.peek() == '\t') {
+		p.pos++
+	}
+}
+
+func (p *parser) eof() bool {
+	return p.pos >= len(p.input)
+}
+
+func (p *parser) peek() byte {
+	return p.input[p.pos]
+}
+
+func (p *parser) rest() string {
+	return p.input[p.pos:]
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return &SyntaxError{Input: p.input, Offset: p.pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func isLetter(b byte) bool {
+	return b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z'
+}
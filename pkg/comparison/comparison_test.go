@@ -0,0 +1,101 @@
+package comparison
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{">10", ">10"},
+		{" >= 10 ", ">=10"},
+		{"=0", "=0"},
+		{"!=3", "!=3"},
+		{"<=-5", "<=-5"},
+		{">= -5", ">=-5"},
+		{">=10,<100", ">=10,<100"},
+		{">=10 , < 100, !=50", ">=10,<100,!=50"},
+		{"between 10 and 100", ">=10,<=100"},
+		{"Between -10 AND 10,!=0", ">=-10,<=10,!=0"},
+	}
+	for _, test := range tests {
+		c, err := Parse(test.input)
+		if err != nil {
+			t.Errorf("Parse(%q) returned error %v", test.input, err)
+			continue
+		}
+		if got := c.String(); got != test.want {
+			t.Errorf("Parse(%q) = %s; want %s", test.input, got, test.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		input  string
+		offset int
+	}{
+		{"", 0},
+		{"   ", 3},
+		{"abc", 0},
+		{"10", 0},
+		{">", 1},
+		{">=abc", 2},
+		{">=10,", 5},
+		{">=10 <100", 5},
+		{">=10abc", 4},
+		{"between 10", 10},
+		{"between 10 or 100", 11},
+		{"between 100 and 10", 7},
+		{">99999999999999999999", 1},
+	}
+	for _, test := range tests {
+		_, err := Parse(test.input)
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Errorf("Parse(%q) returned error %v; want a *SyntaxError", test.input, err)
+			continue
+		}
+		if syntaxErr.Offset != test.offset {
+			t.Errorf("Parse(%q) returned error %q at offset %d; want offset %d", test.input, err, syntaxErr.Offset, test.offset)
+		}
+	}
+}
+
+func TestMatches(t *testing.T) {
+	tests := []struct {
+		comparison string
+		value      int
+		want       bool
+	}{
+		{">10", 11, true},
+		{">10", 10, false},
+		{">=10", 10, true},
+		{"<10", 10, false},
+		{"<=10", 10, true},
+		{"=10", 10, true},
+		{"!=10", 10, false},
+		{">=-5", -5, true},
+		{">=10,<100", 99, true},
+		{">=10,<100", 100, false},
+		{"between 10 and 100", 100, true},
+		{"between 10 and 100", 9, false},
+	}
+	for _, test := range tests {
+		if got := mustParse(t, test.comparison).Matches(test.value); got != test.want {
+			t.Errorf("%q.Matches(%d) = %v; want %v", test.comparison, test.value, got, test.want)
+		}
+	}
+}
+
+func mustParse(t *testing.T, s string) Comparison {
+	t.Helper()
+	c, err := Parse(s)
+	if err != nil {
+		t.Fatalf("Parse(%q) returned error %v", s, err)
+	}
+	return c
+}
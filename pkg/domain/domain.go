@@ -0,0 +1,133 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package domain matches the URLs of Hacker News items
+// against the domain filter of an HNews
+package domain
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	appsv1 "github.com/vadasambar/hnews/api/v1"
+)
+
+// pattern is a domain of a domain filter
+type pattern struct {
+	domain string
+	// subdomainsOnly is true for "*.example.com"
+	subdomainsOnly bool
+}
+
+// Matcher matches URLs against a domain filter
+type Matcher struct {
+	include   []pattern
+	exclude   []pattern
+	selfPosts appsv1.SelfPosts
+}
+
+// Compile parses the domains of the filter
+func Compile(filter *appsv1.DomainFilter) (*Matcher, error) {
+	m := &Matcher{selfPosts: appsv1.IncludeSelfPosts}
+	if filter == nil {
+		return m, nil
+	}
+	if filter.SelfPosts != "" {
+		m.selfPosts = filter.SelfPosts
+	}
+
+	for i, domain := range filter.Include {
+		p, err := parse(domain)
+		if err != nil {
+			return nil, fmt.Errorf("include[%d]: %w", i, err)
+		}
+		m.include = append(m.include, p)
+	}
+	for i, domain := range filter.Exclude {
+		p, err := parse(domain)
+		if err != nil {
+			return nil, fmt.Errorf("exclude[%d]: %w", i, err)
+		}
+		m.exclude = append(m.exclude, p)
+	}
+	return m, nil
+}
+
+// Validate returns an error if the domain can't be in a domain filter
+func Validate(domain string) error {
+	_, err := parse(domain)
+	return err
+}
+
+func parse(domain string) (pattern, error) {
+	p := pattern{domain: strings.TrimSuffix(strings.ToLower(strings.TrimSpace(domain)), ".")}
+	if strings.HasPrefix(p.domain, "*.") {
+		p.domain = strings.TrimPrefix(p.domain, "*.")
+		p.subdomainsOnly = true
+	}
+	if errs := validation.IsDNS1123Subdomain(p.domain); len(errs) > 0 {
+		return pattern{}, fmt.Errorf("invalid domain %q: %s", domain, strings.Join(errs, ", "))
+	}
+	return p, nil
+}
+
+// matches tells if the host is on the domain of the pattern
+func (p pattern) matches(host string) bool {
+	if host == p.domain {
+		return !p.subdomainsOnly
+	}
+	return strings.HasSuffix(host, "."+p.domain)
+}
+
+// Matches tells if the URL is on one of the included domains (if there are any)
+// and none of the excluded ones. An empty URL is a self post.
+func (m *Matcher) Matches(rawURL string) bool {
+	if strings.TrimSpace(rawURL) == "" {
+		return m.selfPosts != appsv1.ExcludeSelfPosts
+	}
+	if m.selfPosts == appsv1.OnlySelfPosts {
+		return false
+	}
+
+	host := Host(rawURL)
+	for _, p := range m.exclude {
+		if p.matches(host) {
+			return false
+		}
+	}
+	if len(m.include) == 0 {
+		return true
+	}
+	for _, p := range m.include {
+		if p.matches(host) {
+			return true
+		}
+	}
+	return false
+}
+
+// Host returns the lower case host of the URL without its port,
+// or "" if it can't be parsed
+func Host(rawURL string) string {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+}
@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"testing"
+
+	appsv1 "github.com/vadasambar/hnews/api/v1"
+)
+
+func TestMatches(t *testing.T) {
+	tests := []struct {
+		filter *appsv1.DomainFilter
+		url    string
+		want   bool
+	}{
+		{nil, "https://example.com/a", true},
+		{nil, "", true},
+		{&appsv1.DomainFilter{Include: []string{"github.com"}}, "https://github.com/vadasambar/hnews", true},
+		{&appsv1.DomainFilter{Include: []string{"github.com"}}, "https://gist.GitHub.com:443/x", true},
+		{&appsv1.DomainFilter{Include: []string{"github.com"}}, "https://notgithub.com/", false},
+		{&appsv1.DomainFilter{Include: []string{"github.com"}}, "", true},
+		{&appsv1.DomainFilter{Include: []string{"*.substack.com"}}, "https://someone.substack.com/p/post", true},
+		{&appsv1.DomainFilter{Include: []string{"*.substack.com"}}, "https://substack.com/", false},
+		{&appsv1.DomainFilter{Exclude: []string{"wsj.com", "*.medium.com"}}, "https://www.wsj.com/articles/x", false},
+		{&appsv1.DomainFilter{Exclude: []string{"wsj.com", "*.medium.com"}}, "https://medium.com/x", true},
+		{&appsv1.DomainFilter{Include: []string{"github.com"}, Exclude: []string{"gist.github.com"}}, "https://gist.github.com/x", false},
+		{&appsv1.DomainFilter{SelfPosts: appsv1.ExcludeSelfPosts}, "", false},
+		{&appsv1.DomainFilter{SelfPosts: appsv1.ExcludeSelfPosts}, "https://example.com", true},
+		{&appsv1.DomainFilter{SelfPosts: appsv1.OnlySelfPosts}, "", true},
+		{&appsv1.DomainFilter{SelfPosts: appsv1.OnlySelfPosts}, "https://example.com", false},
+	}
+	for _, test := range tests {
+		m, err := Compile(test.filter)
+		if err != nil {
+			t.Errorf("Compile(%+v) returned error %v", test.filter, err)
+			continue
+		}
+		if got := m.Matches(test.url); got != test.want {
+			t.Errorf("Matches(%q) with %+v = %v; want %v", test.url, test.filter, got, test.want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, domain := range []string{"github.com", "*.substack.com", "GitHub.com", "localhost"} {
+		if err := Validate(domain); err != nil {
+			t.Errorf("Validate(%q) returned error %v", domain, err)
+		}
+	}
+	for _, domain := range []string{"", "https://github.com", "github.com/x", "*github.com", "a.*.com"} {
+		if err := Validate(domain); err == nil {
+			t.Errorf("Validate(%q) returned no error", domain)
+		}
+	}
+}
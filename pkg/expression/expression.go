@@ -0,0 +1,166 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package expression compiles and evaluates the CEL expressions
+// in the filter of an HNews against Hacker News items
+package expression
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/checker/decls"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+
+	appsv1 "github.com/vadasambar/hnews/api/v1"
+)
+
+// itemType is the CEL type of the `item` variable
+const itemType = "hnews.Item"
+
+// field is a field of `item`
+type field struct {
+	typ *exprpb.Type
+	get func(item *appsv1.GetIdResponse) interface{}
+}
+
+// fields are the fields of `item`, named like in the API's JSON
+var fields = map[string]field{
+	"id": {decls.Int, func(item *appsv1.GetIdResponse) interface{} {
+		return int64(item.ID)
+	}},
+	"score": {decls.Int, func(item *appsv1.GetIdResponse) interface{} {
+		return int64(item.Score)
+	}},
+	"descendants": {decls.Int, func(item *appsv1.GetIdResponse) interface{} {
+		return int64(item.Descendants)
+	}},
+	"title": {decls.String, func(item *appsv1.GetIdResponse) interface{} {
+		return item.Title
+	}},
+	"url": {decls.String, func(item *appsv1.GetIdResponse) interface{} {
+		return item.URL
+	}},
+	"by": {decls.String, func(item *appsv1.GetIdResponse) interface{} {
+		return item.By
+	}},
+	"time": {decls.Timestamp, func(item *appsv1.GetIdResponse) interface{} {
+		return time.Unix(int64(item.Time), 0).UTC()
+	}},
+	"type": {decls.String, func(item *appsv1.GetIdResponse) interface{} {
+		return string(item.Type)
+	}},
+	"kids": {decls.NewListType(decls.Int), func(item *appsv1.GetIdResponse) interface{} {
+		kids := make([]int64, len(item.Kids))
+		for i, kid := range item.Kids {
+			kids[i] = int64(kid)
+		}
+		return kids
+	}},
+}
+
+// env is the CEL environment expressions are compiled in
+var env *cel.Env
+
+func init() {
+	registry, err := types.NewRegistry()
+	if err != nil {
+		panic(err)
+	}
+	env, err = cel.NewEnv(
+		cel.CustomTypeProvider(&itemProvider{TypeProvider: registry}),
+		cel.Variable("item", cel.ObjectType(itemType)),
+	)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Expression is a CEL expression which decides if a Hacker News item
+// is a part of an HNews e.g., `item.score > 100 || item.descendants > 300`
+type Expression struct {
+	program cel.Program
+}
+
+// Compile parses and type-checks the expression.
+// An error is returned if it's invalid or doesn't evaluate to a bool.
+func Compile(expression string) (*Expression, error) {
+	ast, issues := env.Compile(expression)
+	if issues.Err() != nil {
+		return nil, issues.Err()
+	}
+	if !cel.BoolType.IsAssignableType(ast.OutputType()) {
+		return nil, fmt.Errorf("expression has to evaluate to a bool, not %s", ast.OutputType())
+	}
+	program, err := env.Program(ast)
+	if err != nil {
+		return nil, err
+	}
+	return &Expression{program: program}, nil
+}
+
+// Matches evaluates the expression for the item
+func (e *Expression) Matches(item *appsv1.GetIdResponse) (bool, error) {
+	out, _, err := e.program.Eval(map[string]interface{}{"item": item})
+	if err != nil {
+		return false, err
+	}
+	matches, ok := out.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("expression evaluated to %v instead of a bool", out)
+	}
+	return matches, nil
+}
+
+// itemProvider makes `item` a typed object for the type-checker
+// and gets its fields from *appsv1.GetIdResponse at runtime
+type itemProvider struct {
+	ref.TypeProvider
+}
+
+func (p *itemProvider) FindType(typeName string) (*exprpb.Type, bool) {
+	if typeName == itemType {
+		return decls.NewTypeType(decls.NewObjectType(itemType)), true
+	}
+	return p.TypeProvider.FindType(typeName)
+}
+
+func (p *itemProvider) FindFieldType(typeName, fieldName string) (*ref.FieldType, bool) {
+	if typeName != itemType {
+		return p.TypeProvider.FindFieldType(typeName, fieldName)
+	}
+	f, ok := fields[fieldName]
+	if !ok {
+		return nil, false
+	}
+	return &ref.FieldType{
+		Type: f.typ,
+		IsSet: func(target interface{}) bool {
+			item, ok := target.(*appsv1.GetIdResponse)
+			return ok && item != nil
+		},
+		GetFrom: func(target interface{}) (interface{}, error) {
+			item, ok := target.(*appsv1.GetIdResponse)
+			if !ok || item == nil {
+				return nil, fmt.Errorf("unexpected %T for %s", target, itemType)
+			}
+			return f.get(item), nil
+		},
+	}, true
+}
@@ -0,0 +1,63 @@
+package expression
+
+import (
+	"testing"
+
+	appsv1 "github.com/vadasambar/hnews/api/v1"
+)
+
+func TestMatches(t *testing.T) {
+	item := &appsv1.GetIdResponse{
+		ID:          1,
+		By:          "alice",
+		Descendants: 350,
+		Kids:        []int{2, 3},
+		Score:       80,
+		Time:        1650000000, // 2022-04-15T05:20:00Z
+		Title:       "Show HN: A Kubernetes operator",
+		Type:        appsv1.Story,
+		URL:         "https://example.com/operator",
+	}
+
+	tests := []struct {
+		expression string
+		want       bool
+	}{
+		{`item.score > 100 || item.descendants > 300`, true},
+		{`item.score > 100 && item.descendants > 300`, false},
+		{`item.title.startsWith("Show HN") && item.by == "alice"`, true},
+		{`item.type == "job"`, false},
+		{`size(item.kids) == 2 && 3 in item.kids`, true},
+		{`item.url.contains("example.com") && item.id == 1`, true},
+		{`item.time > timestamp("2022-04-15T00:00:00Z")`, true},
+	}
+	for _, test := range tests {
+		e, err := Compile(test.expression)
+		if err != nil {
+			t.Errorf("Compile(%q) returned error %v", test.expression, err)
+			continue
+		}
+		got, err := e.Matches(item)
+		if err != nil {
+			t.Errorf("Matches() for %q returned error %v", test.expression, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("Matches() for %q = %v; want %v", test.expression, got, test.want)
+		}
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	for _, expression := range []string{
+		`item.score >`,
+		`item.score > "100"`,
+		`item.points > 100`,
+		`item.score`,
+		`score > 100`,
+	} {
+		if _, err := Compile(expression); err == nil {
+			t.Errorf("Compile(%q) returned no error", expression)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hnapi
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/util/cache"
+	"k8s.io/utils/clock"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	appsv1 "github.com/vadasambar/hnews/api/v1"
+)
+
+const (
+	// DefaultCacheSize is the maximum number of items in the cache
+	DefaultCacheSize = 10000
+	// DefaultHotTTL is how long a hot item is cached for.
+	// Scores and comments of hot items change every few seconds.
+	DefaultHotTTL = time.Minute
+	// DefaultOldTTL is how long an old item is cached for
+	DefaultOldTTL = time.Hour
+	// DefaultHotAge is the age after which an item isn't hot anymore
+	DefaultHotAge = 48 * time.Hour
+	// DefaultUserCacheSize is the maximum number of user profiles in the cache
+	DefaultUserCacheSize = 10000
+	// DefaultUserTTL is how long a user profile is cached for.
+	// Karma changes slowly and the creation date never does.
+	DefaultUserTTL = time.Hour
+)
+
+var (
+	itemCacheRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "hnews_item_cache_requests_total",
+		Help: "Number of item lookups in the shared item cache, partitioned by result (hit or miss)",
+	}, []string{"result"})
+	itemCacheHits   = itemCacheRequests.WithLabelValues("hit")
+	itemCacheMisses = itemCacheRequests.WithLabelValues("miss")
+
+	userCacheRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "hnews_user_cache_requests_total",
+		Help: "Number of user profile lookups in the shared user cache, partitioned by result (hit or miss)",
+	}, []string{"result"})
+	userCacheHits   = userCacheRequests.WithLabelValues("hit")
+	userCacheMisses = userCacheRequests.WithLabelValues("miss")
+)
+
+func init() {
+	metrics.Registry.MustRegister(itemCacheRequests, userCacheRequests)
+}
+
+// CacheOptions configures the cache used by CachingClient
+type CacheOptions struct {
+	// Size is the maximum number of items in the cache.
+	// The least recently used item is evicted when it is full.
+	Size int
+	// HotTTL is how long items posted in the last HotAge are cached for
+	HotTTL time.Duration
+	// OldTTL is how long the rest of the items are cached for
+	OldTTL time.Duration
+	// HotAge is the age after which an item isn't hot anymore
+	HotAge time.Duration
+	// UserSize is the maximum number of user profiles in the cache
+	UserSize int
+	// UserTTL is how long user profiles are cached for
+	UserTTL time.Duration
+	// Clock is used to expire items and to find their age
+	Clock clock.PassiveClock
+}
+
+// CachingClient is a Client which caches items and user profiles in memory.
+// A single CachingClient is meant to be shared by all the reconciles
+// so that an item is fetched once no matter how many HNews need it.
+type CachingClient struct {
+	Client
+	items *cache.LRUExpireCache
+	users *cache.LRUExpireCache
+	opts  CacheOptions
+}
+
+var (
+	_ Client      = &CachingClient{}
+	_ Invalidator = &CachingClient{}
+)
+
+// NewCachingClient returns a CachingClient which caches
+// the items and user profiles returned by c
+func NewCachingClient(c Client, opts CacheOptions) *CachingClient {
+	if opts.Size <= 0 {
+		opts.Size = DefaultCacheSize
+	}
+	if opts.HotTTL == 0 {
+		opts.HotTTL = DefaultHotTTL
+	}
+	if opts.OldTTL == 0 {
+		opts.OldTTL = DefaultOldTTL
+	}
+	if opts.HotAge == 0 {
+		opts.HotAge = DefaultHotAge
+	}
+	if opts.UserSize <= 0 {
+		opts.UserSize = DefaultUserCacheSize
+	}
+	if opts.UserTTL == 0 {
+		opts.UserTTL = DefaultUserTTL
+	}
+	if opts.Clock == nil {
+		opts.Clock = clock.RealClock{}
+	}
+
+	return &CachingClient{
+		Client: c,
+		items:  cache.NewLRUExpireCacheWithClock(opts.Size, opts.Clock),
+		users:  cache.NewLRUExpireCacheWithClock(opts.UserSize, opts.Clock),
+		opts:   opts,
+	}
+}
+
+// Item returns the item from the cache if it's there
+// and fetches (and caches) it otherwise
+func (c *CachingClient) Item(ctx context.Context, id int) (*appsv1.GetIdResponse, error) {
+	if item, ok := c.items.Get(id); ok {
+		itemCacheHits.Inc()
+		// callers are free to modify what they get back
+		return item.(*appsv1.GetIdResponse).DeepCopy(), nil
+	}
+	itemCacheMisses.Inc()
+
+	item, err := c.Client.Item(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	c.items.Add(id, item.DeepCopy(), c.ttl(item))
+	return item, nil
+}
+
+// Invalidate removes the items from the cache
+// so that they are fetched again the next time they are needed
+func (c *CachingClient) Invalidate(ids ...int) {
+	for _, id := range ids {
+		c.items.Remove(id)
+	}
+}
+
+// User returns the user profile from the cache if it's there
+// and fetches (and caches) it otherwise
+func (c *CachingClient) User(ctx context.Context, id string) (*appsv1.GetUserResponse, error) {
+	if user, ok := c.users.Get(id); ok {
+		userCacheHits.Inc()
+		return user.(*appsv1.GetUserResponse).DeepCopy(), nil
+	}
+	userCacheMisses.Inc()
+
+	user, err := c.Client.User(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	c.users.Add(id, user.DeepCopy(), c.opts.UserTTL)
+	return user, nil
+}
+
+// InvalidateUsers removes the user profiles from the cache
+// so that they are fetched again the next time they are needed
+func (c *CachingClient) InvalidateUsers(ids ...string) {
+	for _, id := range ids {
+		c.users.Remove(id)
+	}
+}
+
+// ttl returns how long the item should be cached for
+func (c *CachingClient) ttl(item *appsv1.GetIdResponse) time.Duration {
+	posted := time.Unix(int64(item.Time), 0)
+	if c.opts.Clock.Since(posted) < c.opts.HotAge {
+		return c.opts.HotTTL
+	}
+	return c.opts.OldTTL
+}
@@ -0,0 +1,107 @@
+package hnapi
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	testingclock "k8s.io/utils/clock/testing"
+)
+
+func TestCachingClient(t *testing.T) {
+	now := time.Unix(1650000000, 0)
+	clock := testingclock.NewFakeClock(now)
+
+	fake := newFakeClient(2)
+	// item 1 is hot, item 2 is old
+	fake.items[1].Time = int(now.Add(-time.Hour).Unix())
+	fake.items[2].Time = int(now.Add(-72 * time.Hour).Unix())
+
+	c := NewCachingClient(fake, CacheOptions{
+		HotTTL: time.Minute,
+		OldTTL: time.Hour,
+		HotAge: 24 * time.Hour,
+		Clock:  clock,
+	})
+	ctx := context.Background()
+	get := func(id int) {
+		t.Helper()
+		item, err := c.Item(ctx, id)
+		if err != nil || item.ID != id {
+			t.Fatalf("Item(%d) = %+v, %v", id, item, err)
+		}
+		// modifying the returned item doesn't modify the cache
+		item.Score = -1
+	}
+	assertFetched := func(want int32) {
+		t.Helper()
+		if fake.fetched != want {
+			t.Errorf("fetched %d items from the API; want %d", fake.fetched, want)
+		}
+	}
+
+	get(1)
+	get(2)
+	get(1)
+	get(2)
+	assertFetched(2)
+
+	clock.Step(2 * time.Minute)
+	get(1)
+	get(2)
+	assertFetched(3)
+
+	c.Invalidate(2)
+	get(2)
+	assertFetched(4)
+
+	if item, _ := c.Item(ctx, 1); item.Score != 10 {
+		t.Errorf("got score %d from the cache; want 10", item.Score)
+	}
+}
+
+func TestCachingClientUsers(t *testing.T) {
+	clock := testingclock.NewFakeClock(time.Unix(1650000000, 0))
+	fake := newFakeClient(0)
+	c := NewCachingClient(fake, CacheOptions{UserTTL: time.Hour, Clock: clock})
+	ctx := context.Background()
+	get := func(id string) {
+		t.Helper()
+		user, err := c.User(ctx, id)
+		if err != nil || user.ID != id {
+			t.Fatalf("User(%q) = %+v, %v", id, user, err)
+		}
+		user.Karma = -1
+	}
+	assertFetched := func(want int32) {
+		t.Helper()
+		if fake.fetchedUsers != want {
+			t.Errorf("fetched %d users from the API; want %d", fake.fetchedUsers, want)
+		}
+	}
+
+	get("alice")
+	get("alice")
+	get("bob")
+	assertFetched(2)
+
+	clock.Step(2 * time.Hour)
+	get("alice")
+	assertFetched(3)
+
+	c.InvalidateUsers("alice")
+	get("alice")
+	assertFetched(4)
+
+	if user, _ := c.User(ctx, "bob"); user.Karma != 100 {
+		t.Errorf("got karma %d from the cache; want 100", user.Karma)
+	}
+	// users which don't exist aren't cached
+	for i := 0; i < 2; i++ {
+		if _, err := c.User(ctx, "ghost"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("got error %v; want %v", err, ErrNotFound)
+		}
+	}
+	assertFetched(7)
+}
@@ -0,0 +1,255 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package hnapi is a small client for the Hacker News API
+// documented at https://github.com/HackerNews/API
+package hnapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"k8s.io/utils/clock"
+
+	appsv1 "github.com/vadasambar/hnews/api/v1"
+)
+
+const (
+	// DefaultBaseURL is the public Hacker News API
+	DefaultBaseURL = "https://hacker-news.firebaseio.com/v0"
+	// DefaultTimeout is the timeout used for a single request
+	// when Options.Timeout is not set
+	DefaultTimeout = 10 * time.Second
+	// DefaultStreamIdleTimeout is used when Options.StreamIdleTimeout is not set.
+	// Firebase sends a keep-alive event every 30 seconds.
+	DefaultStreamIdleTimeout = 90 * time.Second
+	// DefaultUserAgent is sent with every request
+	// when Options.UserAgent is not set
+	DefaultUserAgent = "hnews-controller"
+)
+
+// Feed is one of the story lists exposed by the API
+// e.g., /topstories.json
+type Feed string
+
+const (
+	TopStories  Feed = "topstories"
+	NewStories  Feed = "newstories"
+	BestStories Feed = "beststories"
+	AskStories  Feed = "askstories"
+	ShowStories Feed = "showstories"
+	JobStories  Feed = "jobstories"
+)
+
+// ErrNotFound is returned when the API responds with `null`
+// which is what it does for items and users that don't exist
+var ErrNotFound = errors.New("not found")
+
+// Client is the interface the controller uses to talk to the Hacker News API.
+// It is an interface so that the API can be swapped with a mirror,
+// a caching layer or a fake in tests.
+type Client interface {
+	// Feed returns the item ids in the feed, in the order of their rank
+	Feed(ctx context.Context, feed Feed) ([]int, error)
+	// Item returns the item with the id
+	Item(ctx context.Context, id int) (*appsv1.GetIdResponse, error)
+	// User returns the user profile with the (case-sensitive) id
+	User(ctx context.Context, id string) (*appsv1.GetUserResponse, error)
+	// MaxItem returns the largest item id
+	MaxItem(ctx context.Context) (int, error)
+	// Updates returns the items and user profiles which changed recently
+	Updates(ctx context.Context) (*appsv1.GetUpdatesResponse, error)
+}
+
+// Invalidator is implemented by the clients which cache what they get from the API
+type Invalidator interface {
+	// Invalidate removes the items from the cache
+	Invalidate(ids ...int)
+	// InvalidateUsers removes the user profiles from the cache
+	InvalidateUsers(ids ...string)
+}
+
+// Options configures the Client returned by New
+type Options struct {
+	// BaseURL is the URL all the API paths are appended to
+	// e.g., https://hacker-news.firebaseio.com/v0
+	BaseURL string
+	// Timeout is the timeout for a single request
+	Timeout time.Duration
+	// StreamIdleTimeout is how long a stream can go without any event
+	// (keep-alives included) before it's considered broken.
+	// DefaultStreamIdleTimeout is used when it is 0.
+	StreamIdleTimeout time.Duration
+	// UserAgent is sent as the User-Agent header with every request
+	UserAgent string
+	// Transport is used to make the requests.
+	// http.DefaultTransport is used when it is nil.
+	Transport http.RoundTripper
+	// Retry configures how failed requests are retried
+	Retry RetryOptions
+	// Breaker configures the circuit breaker shared by all the requests
+	Breaker BreakerOptions
+	// RateLimit configures the rate limiter shared by all the requests
+	RateLimit RateLimitOptions
+}
+
+type client struct {
+	baseURL    string
+	userAgent  string
+	httpClient *http.Client
+	// streamIdleTimeout is Options.StreamIdleTimeout
+	streamIdleTimeout time.Duration
+	retry             RetryOptions
+	breaker           *breaker
+	limiter           *limiter
+}
+
+// New returns a Client which talks to the API over HTTP
+func New(opts Options) Client {
+	if opts.BaseURL == "" {
+		opts.BaseURL = DefaultBaseURL
+	}
+	if opts.Timeout == 0 {
+		opts.Timeout = DefaultTimeout
+	}
+	if opts.StreamIdleTimeout == 0 {
+		opts.StreamIdleTimeout = DefaultStreamIdleTimeout
+	}
+	if opts.UserAgent == "" {
+		opts.UserAgent = DefaultUserAgent
+	}
+	opts.Retry.setDefaults()
+	opts.Breaker.setDefaults()
+	opts.RateLimit.setDefaults()
+
+	return &client{
+		baseURL:   strings.TrimSuffix(opts.BaseURL, "/"),
+		userAgent: opts.UserAgent,
+		httpClient: &http.Client{
+			Timeout:   opts.Timeout,
+			Transport: opts.Transport,
+		},
+		streamIdleTimeout: opts.StreamIdleTimeout,
+		retry:             opts.Retry,
+		breaker:           &breaker{opts: opts.Breaker, clock: clock.RealClock{}},
+		limiter:           newLimiter(opts.RateLimit),
+	}
+}
+
+func (c *client) Feed(ctx context.Context, feed Feed) ([]int, error) {
+	var ids []int
+	if err := c.get(ctx, fmt.Sprintf("/%s.json", feed), &ids); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (c *client) Item(ctx context.Context, id int) (*appsv1.GetIdResponse, error) {
+	var item *appsv1.GetIdResponse
+	if err := c.get(ctx, fmt.Sprintf("/item/%d.json", id), &item); err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return nil, fmt.Errorf("item %d: %w", id, ErrNotFound)
+	}
+	return item, nil
+}
+
+func (c *client) User(ctx context.Context, id string) (*appsv1.GetUserResponse, error) {
+	var user *appsv1.GetUserResponse
+	if err := c.get(ctx, fmt.Sprintf("/user/%s.json", id), &user); err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, fmt.Errorf("user %s: %w", id, ErrNotFound)
+	}
+	return user, nil
+}
+
+func (c *client) MaxItem(ctx context.Context) (int, error) {
+	var id int
+	if err := c.get(ctx, "/maxitem.json", &id); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+func (c *client) Updates(ctx context.Context) (*appsv1.GetUpdatesResponse, error) {
+	var updates appsv1.GetUpdatesResponse
+	if err := c.get(ctx, "/updates.json", &updates); err != nil {
+		return nil, err
+	}
+	return &updates, nil
+}
+
+// get does a GET on the path and unmarshals the json response into v.
+// The request is rate limited, retried if it fails
+// and it isn't made if the circuit is open.
+func (c *client) get(ctx context.Context, path string, v interface{}) error {
+	probe, err := c.breaker.allow()
+	if err != nil {
+		return err
+	}
+
+	for retry := 1; ; retry++ {
+		if err = c.limiter.wait(ctx); err != nil {
+			break
+		}
+		err = c.do(ctx, path, v)
+		if retry > c.retry.MaxRetries || !unhealthy(ctx, err) {
+			break
+		}
+		if sleep(ctx, c.retry.backoff(retry, err)) != nil {
+			break
+		}
+	}
+	c.breaker.done(ctx, probe, err)
+	return err
+}
+
+// do does a single GET on the path and unmarshals the json response into v
+func (c *client) do(ctx context.Context, path string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", c.userAgent)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error getting response from %s API: %w", path, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &StatusError{Path: path, StatusCode: resp.StatusCode, RetryAfter: retryAfter(resp)}
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("error reading response from %s API: %w", path, err)
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("error unmarshalling %s API response: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package hnapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClient(t *testing.T) {
+	var userAgent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.Header.Get("User-Agent")
+		switch r.URL.Path {
+		case "/v0/showstories.json":
+			_, _ = w.Write([]byte("[3,1,2]"))
+		case "/v0/item/1.json":
+			_, _ = w.Write([]byte(`{"by":"pg","id":1,"score":57,"type":"story","title":"Y Combinator"}`))
+		case "/v0/user/pg.json":
+			_, _ = w.Write([]byte(`{"id":"pg","karma":155111,"created":1160418092}`))
+		case "/v0/maxitem.json":
+			_, _ = w.Write([]byte("8863"))
+		case "/v0/updates.json":
+			_, _ = w.Write([]byte(`{"items":[8423305,8420805],"profiles":["thefox"]}`))
+		default:
+			_, _ = w.Write([]byte("null"))
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	c := New(Options{BaseURL: server.URL + "/v0/", UserAgent: "test-agent"})
+
+	ids, err := c.Feed(ctx, ShowStories)
+	if err != nil || len(ids) != 3 || ids[0] != 3 {
+		t.Errorf("Feed() = %v, %v; want [3 1 2]", ids, err)
+	}
+	if userAgent != "test-agent" {
+		t.Errorf("User-Agent = %q; want %q", userAgent, "test-agent")
+	}
+
+	item, err := c.Item(ctx, 1)
+	if err != nil || item.By != "pg" || item.Score != 57 {
+		t.Errorf("Item(1) = %+v, %v", item, err)
+	}
+	if _, err := c.Item(ctx, 2); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Item(2) error = %v; want ErrNotFound", err)
+	}
+
+	user, err := c.User(ctx, "pg")
+	if err != nil || user.Karma != 155111 {
+		t.Errorf("User(pg) = %+v, %v", user, err)
+	}
+
+	max, err := c.MaxItem(ctx)
+	if err != nil || max != 8863 {
+		t.Errorf("MaxItem() = %d, %v; want 8863", max, err)
+	}
+
+	updates, err := c.Updates(ctx)
+	if err != nil || len(updates.Items) != 2 || updates.Profiles[0] != "thefox" {
+		t.Errorf("Updates() = %+v, %v", updates, err)
+	}
+}
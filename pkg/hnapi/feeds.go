@@ -0,0 +1,43 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hnapi
+
+import (
+	"context"
+)
+
+// Feeds returns the item ids of all the feeds, one feed after the other
+// in the order they are passed. An id which is in more than one feed
+// is only returned the first time it's seen.
+func Feeds(ctx context.Context, c Client, feeds ...Feed) ([]int, error) {
+	ids := []int{}
+	seen := map[int]bool{}
+	for _, feed := range feeds {
+		feedIds, err := c.Feed(ctx, feed)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range feedIds {
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
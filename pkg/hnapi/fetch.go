@@ -0,0 +1,163 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hnapi
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	appsv1 "github.com/vadasambar/hnews/api/v1"
+)
+
+// DefaultConcurrency is the number of items fetched at the same time
+// when FetchOptions.Concurrency is not set
+const DefaultConcurrency = 8
+
+// Result is an item returned by FetchItems
+type Result struct {
+	// Rank is the (0 based) position of the item in the ids passed to FetchItems
+	Rank int
+	Item *appsv1.GetIdResponse
+}
+
+// FetchOptions configures FetchItems
+type FetchOptions struct {
+	// Concurrency is the maximum number of items fetched at the same time
+	Concurrency int
+	// Limit is the number of accepted items after which fetching stops.
+	// 0 means all the items are fetched.
+	Limit int
+	// Accept decides if an item is a part of the result.
+	// All the items are accepted when it is nil. It is called by the
+	// workers (so concurrently) and it can make requests of its own
+	// e.g., for the author of the item. An error is handled like
+	// an error fetching the item.
+	Accept func(ctx context.Context, item *appsv1.GetIdResponse) (bool, error)
+	// OnError decides if an item which couldn't be fetched is skipped (true)
+	// or fetching stops with the error (false). Fetching stops when it is nil.
+	OnError func(rank int, err error) bool
+}
+
+// outcome is what a worker sends back for a single id
+type outcome struct {
+	rank     int
+	item     *appsv1.GetIdResponse
+	accepted bool
+	err      error
+}
+
+// FetchItems fetches the items with a pool of workers and returns the accepted ones
+// in the order of ids. Fetching stops as soon as the first opts.Limit ids (by rank)
+// which are accepted are known, so ids after them are fetched only if a worker was
+// already busy with them. Items which don't exist anymore are skipped
+// and so are the ones which couldn't be fetched if opts.OnError says so.
+func FetchItems(ctx context.Context, c Client, ids []int, opts FetchOptions) ([]Result, error) {
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+	if concurrency > len(ids) {
+		concurrency = len(ids)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	ranks := make(chan int)
+	outcomes := make(chan outcome, concurrency)
+
+	go func() {
+		defer close(ranks)
+		for rank := range ids {
+			select {
+			case ranks <- rank:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for rank := range ranks {
+				o := outcome{rank: rank}
+				o.item, o.err = c.Item(ctx, ids[rank])
+				if o.err == nil {
+					o.accepted = true
+					if opts.Accept != nil {
+						o.accepted, o.err = opts.Accept(ctx, o.item)
+					}
+				}
+				outcomes <- o
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(outcomes)
+	}()
+
+	// outcomes arrive in any order, so they are held in pending
+	// until every id before them has been looked at
+	var (
+		results []Result
+		err     error
+		done    bool
+		next    int
+		pending = map[int]outcome{}
+	)
+	for o := range outcomes {
+		if done {
+			// drain the workers which were busy when we stopped
+			continue
+		}
+		pending[o.rank] = o
+
+		for !done {
+			o, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			next++
+
+			switch {
+			case errors.Is(o.err, ErrNotFound):
+			case o.err != nil:
+				if opts.OnError == nil || !opts.OnError(o.rank, o.err) {
+					err = o.err
+					done = true
+				}
+			case o.accepted:
+				results = append(results, Result{Rank: o.rank, Item: o.item})
+				done = opts.Limit > 0 && len(results) == opts.Limit
+			}
+		}
+		if done {
+			cancel()
+		}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
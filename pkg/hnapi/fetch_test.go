@@ -0,0 +1,137 @@
+package hnapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	appsv1 "github.com/vadasambar/hnews/api/v1"
+)
+
+// fakeClient serves items from memory. Item with an even id
+// takes longer than the one with an odd id so that the items
+// are fetched out of order.
+type fakeClient struct {
+	items map[int]*appsv1.GetIdResponse
+	err   map[int]error
+
+	fetched      int32
+	fetchedUsers int32
+	inFlight     int32
+	maxSeen      int32
+	mu           sync.Mutex
+}
+
+func newFakeClient(n int) *fakeClient {
+	c := &fakeClient{items: map[int]*appsv1.GetIdResponse{}, err: map[int]error{}}
+	for id := 1; id <= n; id++ {
+		c.items[id] = &appsv1.GetIdResponse{ID: id, Score: id * 10, Type: appsv1.Story}
+	}
+	return c
+}
+
+func (c *fakeClient) Feed(ctx context.Context, feed Feed) ([]int, error) {
+	ids := []int{}
+	for id := 1; id <= len(c.items); id++ {
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func (c *fakeClient) Item(ctx context.Context, id int) (*appsv1.GetIdResponse, error) {
+	atomic.AddInt32(&c.fetched, 1)
+	n := atomic.AddInt32(&c.inFlight, 1)
+	defer atomic.AddInt32(&c.inFlight, -1)
+	c.mu.Lock()
+	if n > c.maxSeen {
+		c.maxSeen = n
+	}
+	c.mu.Unlock()
+
+	time.Sleep(time.Millisecond * time.Duration(1+id%2*3))
+	if err := c.err[id]; err != nil {
+		return nil, err
+	}
+	item, ok := c.items[id]
+	if !ok {
+		return nil, fmt.Errorf("item %d: %w", id, ErrNotFound)
+	}
+	return item.DeepCopy(), nil
+}
+
+// User returns a profile for every id except "ghost"
+func (c *fakeClient) User(ctx context.Context, id string) (*appsv1.GetUserResponse, error) {
+	atomic.AddInt32(&c.fetchedUsers, 1)
+	if id == "ghost" {
+		return nil, fmt.Errorf("user %s: %w", id, ErrNotFound)
+	}
+	return &appsv1.GetUserResponse{ID: id, Karma: 100}, nil
+}
+
+func (c *fakeClient) MaxItem(ctx context.Context) (int, error) {
+	return len(c.items), nil
+}
+
+func (c *fakeClient) Updates(ctx context.Context) (*appsv1.GetUpdatesResponse, error) {
+	return &appsv1.GetUpdatesResponse{}, nil
+}
+
+func TestFetchItemsKeepsRank(t *testing.T) {
+	c := newFakeClient(50)
+	ids, _ := c.Feed(context.Background(), TopStories)
+
+	results, err := FetchItems(context.Background(), c, ids, FetchOptions{
+		Concurrency: 4,
+		Limit:       5,
+		Accept: func(ctx context.Context, item *appsv1.GetIdResponse) (bool, error) {
+			return item.ID%3 == 0, nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []int{3, 6, 9, 12, 15}
+	if len(results) != len(want) {
+		t.Fatalf("got %d results; want %d", len(results), len(want))
+	}
+	for i, result := range results {
+		if result.Item.ID != want[i] || result.Rank != want[i]-1 {
+			t.Errorf("results[%d] = rank %d id %d; want rank %d id %d", i, result.Rank, result.Item.ID, want[i]-1, want[i])
+		}
+	}
+
+	if c.maxSeen > 4 {
+		t.Errorf("%d items were fetched at the same time; want at most 4", c.maxSeen)
+	}
+	// 15 ids are needed, the workers may run a little ahead of them
+	if c.fetched >= 30 {
+		t.Errorf("%d items were fetched; want fetching to stop after the limit is reached", c.fetched)
+	}
+}
+
+func TestFetchItemsErrors(t *testing.T) {
+	c := newFakeClient(10)
+	ids := []int{1, 2, 42, 3, 4}
+	boom := errors.New("boom")
+	c.err[4] = boom
+
+	// missing items are skipped
+	results, err := FetchItems(context.Background(), c, ids, FetchOptions{Limit: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 3 || results[2].Item.ID != 3 || results[2].Rank != 3 {
+		t.Errorf("got %+v; want items 1, 2 and 3", results)
+	}
+
+	// errors after the limit is reached don't matter
+	// but the ones before it do
+	if _, err := FetchItems(context.Background(), c, ids, FetchOptions{}); !errors.Is(err, boom) {
+		t.Errorf("got error %v; want %v", err, boom)
+	}
+}
@@ -0,0 +1,194 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hnapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	// DefaultQPS is the number of requests per second
+	// allowed by the global rate limiter
+	DefaultQPS = 20
+	// DefaultBurst is the burst allowed by the global rate limiter
+	DefaultBurst = 40
+)
+
+// ErrRateLimited is returned when ctx is done (or would be)
+// before the rate limiter lets the request through
+var ErrRateLimited = errors.New("rate limited")
+
+// Priority decides which of the requests waiting
+// for the rate limiter are made first
+type Priority int
+
+const (
+	// PriorityHigh is for the requests which refresh what users see
+	// e.g., the status of an HNews. It is the default.
+	PriorityHigh Priority = iota
+	// PriorityLow is for background requests e.g., polling for changes.
+	// They wait while there are high priority requests waiting.
+	PriorityLow
+)
+
+type contextKey int
+
+const (
+	priorityKey contextKey = iota
+	namespaceKey
+)
+
+// WithPriority returns a context which makes the requests
+// made with it have the priority
+func WithPriority(ctx context.Context, priority Priority) context.Context {
+	return context.WithValue(ctx, priorityKey, priority)
+}
+
+// WithNamespace returns a context which makes the requests made with it
+// count against the budget of the namespace (as well as the global one)
+func WithNamespace(ctx context.Context, namespace string) context.Context {
+	return context.WithValue(ctx, namespaceKey, namespace)
+}
+
+// RateLimitOptions configures the token bucket rate limiter
+// which all the requests of a client go through
+type RateLimitOptions struct {
+	// QPS is the number of requests per second.
+	// Requests aren't limited if it's negative.
+	QPS float64
+	// Burst is the number of requests which can be made at once
+	Burst int
+	// NamespaceQPS is the number of requests per second for every namespace,
+	// on top of QPS. Namespaces don't have a budget of their own if it's 0.
+	NamespaceQPS float64
+	// NamespaceBurst is the number of requests which can be made at once for every namespace
+	NamespaceBurst int
+}
+
+func (o *RateLimitOptions) setDefaults() {
+	if o.QPS == 0 {
+		o.QPS = DefaultQPS
+	}
+	if o.Burst == 0 {
+		o.Burst = DefaultBurst
+	}
+	if o.NamespaceBurst == 0 {
+		o.NamespaceBurst = int(o.NamespaceQPS) + 1
+	}
+}
+
+// limiter is a rate limiter which lets high priority requests go first
+type limiter struct {
+	opts   RateLimitOptions
+	global *rate.Limiter
+
+	mu          sync.Mutex
+	namespaces  map[string]*rate.Limiter
+	highWaiting int
+	// highDone is closed when there are no more
+	// high priority requests waiting
+	highDone chan struct{}
+}
+
+func newLimiter(opts RateLimitOptions) *limiter {
+	if opts.QPS < 0 {
+		return nil
+	}
+	return &limiter{
+		opts:       opts,
+		global:     rate.NewLimiter(rate.Limit(opts.QPS), opts.Burst),
+		namespaces: map[string]*rate.Limiter{},
+	}
+}
+
+// wait blocks until the request can be made or ctx is done
+func (l *limiter) wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	if err := l.waitFor(ctx); err != nil {
+		return fmt.Errorf("%w: %v", ErrRateLimited, err)
+	}
+	return nil
+}
+
+func (l *limiter) waitFor(ctx context.Context) error {
+	if namespace, ok := ctx.Value(namespaceKey).(string); ok && l.opts.NamespaceQPS > 0 {
+		if err := l.namespace(namespace).Wait(ctx); err != nil {
+			return err
+		}
+	}
+
+	if priority, _ := ctx.Value(priorityKey).(Priority); priority == PriorityLow {
+		if err := l.waitForHigh(ctx); err != nil {
+			return err
+		}
+		return l.global.Wait(ctx)
+	}
+
+	l.mu.Lock()
+	l.highWaiting++
+	if l.highDone == nil {
+		l.highDone = make(chan struct{})
+	}
+	l.mu.Unlock()
+	defer func() {
+		l.mu.Lock()
+		l.highWaiting--
+		if l.highWaiting == 0 {
+			close(l.highDone)
+			l.highDone = nil
+		}
+		l.mu.Unlock()
+	}()
+	return l.global.Wait(ctx)
+}
+
+// waitForHigh blocks until no high priority requests are waiting
+func (l *limiter) waitForHigh(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		highDone := l.highDone
+		l.mu.Unlock()
+		if highDone == nil {
+			return nil
+		}
+
+		select {
+		case <-highDone:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// namespace returns the limiter of the namespace
+func (l *limiter) namespace(namespace string) *rate.Limiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	ns, ok := l.namespaces[namespace]
+	if !ok {
+		ns = rate.NewLimiter(rate.Limit(l.opts.NamespaceQPS), l.opts.NamespaceBurst)
+		l.namespaces[namespace] = ns
+	}
+	return ns
+}
@@ -0,0 +1,62 @@
+package hnapi
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLimiterPriority(t *testing.T) {
+	// a token every 20ms and none to start with
+	l := newLimiter(RateLimitOptions{QPS: 50, Burst: 1})
+	_ = l.wait(context.Background())
+
+	var mu sync.Mutex
+	order := []Priority{}
+	var wg sync.WaitGroup
+	start := func(priority Priority) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := l.wait(WithPriority(context.Background(), priority)); err != nil {
+				t.Error(err)
+			}
+			mu.Lock()
+			order = append(order, priority)
+			mu.Unlock()
+		}()
+	}
+
+	start(PriorityHigh)
+	time.Sleep(5 * time.Millisecond)
+	start(PriorityLow)
+	time.Sleep(5 * time.Millisecond)
+	start(PriorityHigh)
+	wg.Wait()
+
+	want := []Priority{PriorityHigh, PriorityHigh, PriorityLow}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("requests were let through in the order %v; want %v", order, want)
+		}
+	}
+}
+
+func TestLimiterNamespace(t *testing.T) {
+	l := newLimiter(RateLimitOptions{QPS: 1000, Burst: 100, NamespaceQPS: 0.001, NamespaceBurst: 1})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	busy := WithNamespace(ctx, "busy")
+	if err := l.wait(busy); err != nil {
+		t.Fatalf("got error %v; want the namespace's burst to be let through", err)
+	}
+	if err := l.wait(busy); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("got error %v; want %v once the namespace is over its budget", err, ErrRateLimited)
+	}
+	if err := l.wait(WithNamespace(ctx, "quiet")); err != nil {
+		t.Errorf("got error %v; want other namespaces to have their own budget", err)
+	}
+}
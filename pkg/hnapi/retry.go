@@ -0,0 +1,235 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hnapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"k8s.io/utils/clock"
+)
+
+const (
+	// DefaultMaxRetries is the number of times a failed request is retried
+	DefaultMaxRetries = 3
+	// DefaultMinBackoff is the backoff before the first retry
+	DefaultMinBackoff = 200 * time.Millisecond
+	// DefaultMaxBackoff is the longest backoff between retries
+	DefaultMaxBackoff = 5 * time.Second
+	// DefaultBreakerThreshold is the number of requests in a row
+	// which have to fail (after retries) for the circuit to open
+	DefaultBreakerThreshold = 5
+	// DefaultBreakerCooldown is how long the circuit stays open
+	DefaultBreakerCooldown = 30 * time.Second
+)
+
+// ErrCircuitOpen is returned without making the request
+// when the API has been failing and the circuit is open
+var ErrCircuitOpen = errors.New("circuit open: the API is unhealthy")
+
+// StatusError is returned when the API responds with a non 2xx status code
+type StatusError struct {
+	Path       string
+	StatusCode int
+	// RetryAfter is the Retry-After the API responded with, if any
+	RetryAfter time.Duration
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status %d %s from %s API", e.StatusCode, http.StatusText(e.StatusCode), e.Path)
+}
+
+// CircuitOpenError is returned while the circuit is open.
+// errors.Is(err, ErrCircuitOpen) is true for it.
+type CircuitOpenError struct {
+	// Until is when the circuit lets a request through again
+	Until time.Time
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("%s until %s", ErrCircuitOpen, e.Until.Format(time.RFC3339))
+}
+
+func (e *CircuitOpenError) Is(target error) bool {
+	return target == ErrCircuitOpen
+}
+
+// RetryOptions configures how failed requests are retried.
+// Requests are retried if they fail with a network error,
+// a 5xx or a 429 status code.
+type RetryOptions struct {
+	// MaxRetries is the number of times a request is retried.
+	// Requests aren't retried if it's negative.
+	MaxRetries int
+	// MinBackoff is the backoff before the first retry,
+	// it doubles with every retry
+	MinBackoff time.Duration
+	// MaxBackoff is the longest backoff between retries
+	MaxBackoff time.Duration
+}
+
+// BreakerOptions configures the circuit breaker which stops all requests
+// for a while when the API keeps failing
+type BreakerOptions struct {
+	// Threshold is the number of requests in a row which have to fail
+	// (after retries) for the circuit to open.
+	// The circuit never opens if it's negative.
+	Threshold int
+	// Cooldown is how long the circuit stays open. A single request is let
+	// through after it and the circuit closes again if it succeeds.
+	Cooldown time.Duration
+}
+
+func (o *RetryOptions) setDefaults() {
+	if o.MaxRetries == 0 {
+		o.MaxRetries = DefaultMaxRetries
+	}
+	if o.MinBackoff == 0 {
+		o.MinBackoff = DefaultMinBackoff
+	}
+	if o.MaxBackoff == 0 {
+		o.MaxBackoff = DefaultMaxBackoff
+	}
+}
+
+func (o *BreakerOptions) setDefaults() {
+	if o.Threshold == 0 {
+		o.Threshold = DefaultBreakerThreshold
+	}
+	if o.Cooldown == 0 {
+		o.Cooldown = DefaultBreakerCooldown
+	}
+}
+
+// backoff returns the backoff before the retry
+// e.g., the 1st retry waits for up to MinBackoff, the 2nd up to 2*MinBackoff
+// and so on with full jitter. Retry-After is honored if the API sent it.
+func (o *RetryOptions) backoff(retry int, err error) time.Duration {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) && statusErr.RetryAfter > 0 {
+		return statusErr.RetryAfter
+	}
+
+	backoff := o.MinBackoff << (retry - 1)
+	if backoff > o.MaxBackoff || backoff <= 0 {
+		backoff = o.MaxBackoff
+	}
+	return time.Duration(rand.Int63n(int64(backoff)) + 1)
+}
+
+// retryAfter parses the Retry-After header (in seconds)
+func retryAfter(resp *http.Response) time.Duration {
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// breaker is a circuit breaker. It is closed (lets requests through) until
+// Threshold requests fail in a row, then it is open (fails requests right away)
+// for Cooldown and then it lets one request through to see if the API is back.
+type breaker struct {
+	opts  BreakerOptions
+	clock clock.PassiveClock
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+// allow returns an error if the circuit is open. It tells if the request
+// is the probe which finds out if the API is back when the circuit is half open.
+func (b *breaker) allow() (bool, error) {
+	if b.opts.Threshold < 0 {
+		return false, nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failures < b.opts.Threshold {
+		return false, nil
+	}
+	if b.clock.Now().Before(b.openUntil) || b.probing {
+		return false, &CircuitOpenError{Until: b.openUntil}
+	}
+	// half open: let this request find out if the API is back
+	b.probing = true
+	return true, nil
+}
+
+// done records the outcome of a request which was allowed.
+// Once the circuit is open, only the outcome of the probe counts as
+// the requests which were allowed before it opened say nothing new.
+func (b *breaker) done(ctx context.Context, probe bool, err error) {
+	if b.opts.Threshold < 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if probe {
+		b.probing = false
+	}
+	if ctx.Err() != nil || errors.Is(err, ErrRateLimited) {
+		// the request says nothing about the API
+		return
+	}
+	if b.failures >= b.opts.Threshold && !probe {
+		return
+	}
+	if !unhealthy(ctx, err) {
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.failures >= b.opts.Threshold {
+		b.openUntil = b.clock.Now().Add(b.opts.Cooldown)
+	}
+}
+
+// unhealthy tells if err means the API is unhealthy (and the request
+// should be retried), as opposed to the item not existing or the caller giving up
+func unhealthy(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil || errors.Is(err, ErrNotFound) || errors.Is(err, ErrRateLimited) {
+		return false
+	}
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= 500 || statusErr.StatusCode == http.StatusTooManyRequests
+	}
+	return true
+}
+
+// sleep waits for d or until ctx is done
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
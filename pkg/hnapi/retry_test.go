@@ -0,0 +1,145 @@
+package hnapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	testingclock "k8s.io/utils/clock/testing"
+)
+
+func TestRetry(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch atomic.AddInt32(&requests, 1) {
+		case 1:
+			http.Error(w, "<html>oops</html>", http.StatusBadGateway)
+		case 2:
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			_, _ = w.Write([]byte("42"))
+		}
+	}))
+	defer server.Close()
+
+	c := New(Options{BaseURL: server.URL, Retry: RetryOptions{MinBackoff: time.Millisecond}})
+	max, err := c.MaxItem(context.Background())
+	if err != nil || max != 42 {
+		t.Errorf("MaxItem() = %d, %v; want 42 after 2 retries", max, err)
+	}
+	if requests != 3 {
+		t.Errorf("got %d requests; want 3", requests)
+	}
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if r.URL.Path == "/item/1.json" {
+			http.Error(w, "gone", http.StatusForbidden)
+			return
+		}
+		http.Error(w, "down", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	c := New(Options{BaseURL: server.URL, Retry: RetryOptions{MaxRetries: 2, MinBackoff: time.Millisecond}})
+	var statusErr *StatusError
+	if _, err := c.MaxItem(context.Background()); !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("got error %v; want a 503 StatusError", err)
+	}
+	if requests != 3 {
+		t.Errorf("got %d requests; want 3", requests)
+	}
+
+	// 4xx aren't retried
+	if _, err := c.Item(context.Background(), 1); !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusForbidden {
+		t.Errorf("got error %v; want a 403 StatusError", err)
+	}
+	if requests != 4 {
+		t.Errorf("got %d requests; want 4", requests)
+	}
+}
+
+func TestBreaker(t *testing.T) {
+	healthy := int32(0)
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if atomic.LoadInt32(&healthy) == 0 {
+			http.Error(w, "down", http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte("42"))
+	}))
+	defer server.Close()
+
+	clock := testingclock.NewFakeClock(time.Now())
+	c := New(Options{
+		BaseURL: server.URL,
+		Retry:   RetryOptions{MaxRetries: -1},
+		Breaker: BreakerOptions{Threshold: 2, Cooldown: time.Minute},
+	}).(*client)
+	c.breaker.clock = clock
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.MaxItem(ctx); err == nil || errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("got error %v; want the API's error", err)
+		}
+	}
+	if _, err := c.MaxItem(ctx); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("got error %v; want %v", err, ErrCircuitOpen)
+	}
+	if requests != 2 {
+		t.Errorf("got %d requests; want none while the circuit is open", requests)
+	}
+
+	atomic.StoreInt32(&healthy, 1)
+	clock.Step(time.Minute)
+	if _, err := c.MaxItem(ctx); err != nil {
+		t.Errorf("got error %v; want the circuit to close", err)
+	}
+	if _, err := c.MaxItem(ctx); err != nil {
+		t.Errorf("got error %v; want the circuit to be closed", err)
+	}
+}
+
+func TestBreakerStaleRequests(t *testing.T) {
+	clock := testingclock.NewFakeClock(time.Now())
+	b := &breaker{opts: BreakerOptions{Threshold: 1, Cooldown: time.Minute}, clock: clock}
+	ctx := context.Background()
+	apiErr := &StatusError{Path: "/maxitem.json", StatusCode: http.StatusInternalServerError}
+
+	// a request is allowed before the circuit opens and finishes after
+	_, err := b.allow()
+	if err != nil {
+		t.Fatalf("got error %v; want the circuit to be closed", err)
+	}
+	probe, _ := b.allow()
+	b.done(ctx, probe, apiErr)
+	if _, err := b.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("got error %v; want %v", err, ErrCircuitOpen)
+	}
+
+	clock.Step(time.Minute)
+	probe, err = b.allow()
+	if err != nil || !probe {
+		t.Fatalf("got probe %v and error %v; want the probe", probe, err)
+	}
+	// the stale request doesn't close the circuit or let another probe through
+	b.done(ctx, false, nil)
+	if _, err := b.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("got error %v; want %v while probing", err, ErrCircuitOpen)
+	}
+	// the probe does
+	b.done(ctx, true, nil)
+	if probe, err := b.allow(); err != nil || probe {
+		t.Errorf("got probe %v and error %v; want the circuit to be closed", probe, err)
+	}
+}
@@ -0,0 +1,176 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hnapi
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Types of the events sent by the Firebase streaming API
+// https://firebase.google.com/docs/reference/rest/database#section-streaming
+const (
+	EventPut         = "put"
+	EventPatch       = "patch"
+	EventKeepAlive   = "keep-alive"
+	EventCancel      = "cancel"
+	EventAuthRevoked = "auth_revoked"
+)
+
+// ErrStreamClosed is returned by Stream when the server closes the stream
+var ErrStreamClosed = errors.New("stream closed by the server")
+
+// ErrStreamTimeout is returned by Stream when the stream doesn't connect
+// in time or goes quiet for too long e.g., when its connection is half-open
+var ErrStreamTimeout = errors.New("stream timed out")
+
+// Event is a server-sent event from the streaming API
+type Event struct {
+	// Type is the type of the event e.g., put
+	Type string
+	// Path is where the data changed relative to the streamed path,
+	// "/" means all of it
+	Path string
+	// Data is the new (json) data at Path
+	Data json.RawMessage
+}
+
+// Streamer streams changes from the API.
+// The Hacker News API is a Firebase database
+// which can stream any of its paths as server-sent events.
+type Streamer interface {
+	// Stream sends the put and patch events of the path (e.g., /topstories.json)
+	// to events until ctx is done or the stream breaks. It always returns an error.
+	Stream(ctx context.Context, path string, events chan<- Event) error
+}
+
+// NewStreamer returns a Streamer which streams from the API over HTTP.
+// Options.Timeout is the time to connect and get the response headers
+// and Options.StreamIdleTimeout the time a stream can go without any event.
+func NewStreamer(opts Options) Streamer {
+	return New(opts).(*client)
+}
+
+var _ Streamer = &client{}
+
+func (c *client) Stream(ctx context.Context, path string, events chan<- Event) error {
+	// the stream is cancelled when the deadline passes, which is
+	// the timeout to connect and then the idle timeout after every line
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var timedOut int32
+	deadline := time.AfterFunc(c.httpClient.Timeout, func() {
+		atomic.StoreInt32(&timedOut, 1)
+		cancel()
+	})
+	defer deadline.Stop()
+	timeoutErr := func(err error) error {
+		if atomic.LoadInt32(&timedOut) == 1 {
+			return fmt.Errorf("%s: %w", path, ErrStreamTimeout)
+		}
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(streamCtx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", c.userAgent)
+	req.Header.Set("Accept", "text/event-stream")
+
+	// the timeout of c.httpClient covers reading the body,
+	// which would end the stream
+	streamClient := &http.Client{Transport: c.httpClient.Transport}
+	resp, err := streamClient.Do(req)
+	if err != nil {
+		return timeoutErr(fmt.Errorf("error streaming %s: %w", path, err))
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("error streaming %s: unexpected status %s", path, resp.Status)
+	}
+
+	// events are separated by a blank line and look like
+	// event: put
+	// data: {"path":"/","data":[31510865,31503201]}
+	var eventType string
+	var data strings.Builder
+	scanner := bufio.NewScanner(resp.Body)
+	// a whole feed is sent in a single line
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	deadline.Reset(c.streamIdleTimeout)
+	for scanner.Scan() {
+		// waiting for events to be received doesn't count
+		deadline.Stop()
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event:"):
+			eventType = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data.WriteString(strings.TrimSpace(strings.TrimPrefix(line, "data:")))
+		case line == "":
+			err := dispatch(streamCtx, path, eventType, data.String(), events)
+			if err != nil {
+				return timeoutErr(err)
+			}
+			eventType = ""
+			data.Reset()
+		}
+		deadline.Reset(c.streamIdleTimeout)
+	}
+	if err := scanner.Err(); err != nil {
+		return timeoutErr(fmt.Errorf("error reading stream %s: %w", path, err))
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return fmt.Errorf("%s: %w", path, ErrStreamClosed)
+}
+
+// dispatch sends a complete event to events
+func dispatch(ctx context.Context, path, eventType, data string, events chan<- Event) error {
+	switch eventType {
+	case EventPut, EventPatch:
+	case EventCancel, EventAuthRevoked:
+		return fmt.Errorf("stream %s was ended by the server with %q: %s", path, eventType, data)
+	default:
+		// keep-alive and unknown events
+		return nil
+	}
+
+	var payload struct {
+		Path string          `json:"path"`
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(data), &payload); err != nil {
+		return fmt.Errorf("error unmarshalling %q event of stream %s: %w", eventType, path, err)
+	}
+
+	select {
+	case events <- Event{Type: eventType, Path: payload.Path, Data: payload.Data}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,97 @@
+package hnapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// sseServer is a stand-in for the Firebase streaming API
+// which sends the events and closes the stream
+func sseServer(events ...string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept") != "text/event-stream" {
+			http.Error(w, "not a stream", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, event := range events {
+			fmt.Fprint(w, event)
+			w.(http.Flusher).Flush()
+		}
+	}))
+}
+
+func TestStream(t *testing.T) {
+	server := sseServer(
+		"event: put\ndata: {\"path\":\"/\",\"data\":[3,1,2]}\n\n",
+		"event: keep-alive\ndata: null\n\n",
+		"event: patch\ndata: {\"path\":\"/\",\"data\":{\"0\":4}}\n\n",
+	)
+	defer server.Close()
+
+	events := make(chan Event, 10)
+	err := NewStreamer(Options{BaseURL: server.URL}).Stream(context.Background(), "/topstories.json", events)
+	if !errors.Is(err, ErrStreamClosed) {
+		t.Errorf("got error %v; want %v", err, ErrStreamClosed)
+	}
+	close(events)
+
+	got := []Event{}
+	for event := range events {
+		got = append(got, event)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d events; want the put and the patch", len(got))
+	}
+	if got[0].Type != EventPut || got[0].Path != "/" || string(got[0].Data) != "[3,1,2]" {
+		t.Errorf("got %+v; want the put", got[0])
+	}
+	if got[1].Type != EventPatch || string(got[1].Data) != `{"0":4}` {
+		t.Errorf("got %+v; want the patch", got[1])
+	}
+}
+
+func TestStreamCancel(t *testing.T) {
+	server := sseServer("event: cancel\ndata: permission denied\n\n")
+	defer server.Close()
+
+	err := NewStreamer(Options{BaseURL: server.URL}).Stream(context.Background(), "/topstories.json", make(chan Event))
+	if err == nil || errors.Is(err, ErrStreamClosed) {
+		t.Errorf("got error %v; want the stream to be cancelled", err)
+	}
+}
+
+func TestStreamTimeout(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+	}{
+		{"connect", func(w http.ResponseWriter, r *http.Request) {
+			<-r.Context().Done()
+		}},
+		{"idle", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/event-stream")
+			// keep-alives keep the stream open until they stop
+			for i := 0; i < 3; i++ {
+				fmt.Fprint(w, "event: keep-alive\ndata: null\n\n")
+				w.(http.Flusher).Flush()
+				time.Sleep(50 * time.Millisecond)
+			}
+			<-r.Context().Done()
+		}},
+	}
+	for _, test := range tests {
+		server := httptest.NewServer(test.handler)
+		streamer := NewStreamer(Options{BaseURL: server.URL, Timeout: 100 * time.Millisecond, StreamIdleTimeout: 100 * time.Millisecond})
+		err := streamer.Stream(context.Background(), "/topstories.json", make(chan Event))
+		if !errors.Is(err, ErrStreamTimeout) {
+			t.Errorf("%s: got error %v; want %v", test.name, err, ErrStreamTimeout)
+		}
+		server.Close()
+	}
+}
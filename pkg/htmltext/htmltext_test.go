@@ -0,0 +1,32 @@
+package htmltext
+
+import "testing"
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		html string
+		want string
+	}{
+		{"plain", "plain"},
+		{"It&#x27;s &quot;fine&quot; &gt; 1", `It's "fine" > 1`},
+		{"first<p>second<p>third", "first\n\nsecond\n\nthird"},
+		{`see <a href="https:&#x2F;&#x2F;example.com&#x2F;a&#x2F;very&#x2F;long&#x2F;path" rel="nofollow">https:&#x2F;&#x2F;example.com&#x2F;a&#x2F;ve...</a> for more`,
+			"see https://example.com/a/very/long/path for more"},
+		{"<i>emphasis</i> and <pre><code>  code\n</code></pre>", "emphasis and   code"},
+		{"", ""},
+	}
+	for _, test := range tests {
+		if got := Convert(test.html); got != test.want {
+			t.Errorf("Convert(%q) = %q; want %q", test.html, got, test.want)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	if got := Truncate("héllo world", 5); got != "héllo…" {
+		t.Errorf("Truncate() = %q; want %q", got, "héllo…")
+	}
+	if got := Truncate("short", 5); got != "short" {
+		t.Errorf("Truncate() = %q; want %q", got, "short")
+	}
+}
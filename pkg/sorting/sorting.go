@@ -0,0 +1,76 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package sorting sorts the Hacker News items of an HNews by its spec.sort
+package sorting
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	appsv1 "github.com/vadasambar/hnews/api/v1"
+	"github.com/vadasambar/hnews/pkg/hnapi"
+)
+
+// Gravity is how fast the hotness of an item falls with its age
+const Gravity = 1.8
+
+// Hotness is the score of the item for its age at now, the way the
+// Hacker News front page ranks stories: (score - 1) / (age in hours + 2)^Gravity
+func Hotness(item *appsv1.GetIdResponse, now time.Time) float64 {
+	age := now.Sub(time.Unix(int64(item.Time), 0)).Hours()
+	if age < 0 {
+		age = 0
+	}
+	return float64(item.Score-1) / math.Pow(age+2, Gravity)
+}
+
+// Sort sorts the results by the order. The results which are equal
+// for the order (and all of them for rank) stay in the order of their rank.
+func Sort(results []hnapi.Result, order appsv1.SortOrder, now time.Time) {
+	var less func(a, b *appsv1.GetIdResponse) bool
+	switch order {
+	case appsv1.SortByScore:
+		less = func(a, b *appsv1.GetIdResponse) bool { return a.Score > b.Score }
+	case appsv1.SortByDescendants:
+		less = func(a, b *appsv1.GetIdResponse) bool { return a.Descendants > b.Descendants }
+	case appsv1.SortByNewest:
+		less = func(a, b *appsv1.GetIdResponse) bool { return a.Time > b.Time }
+	case appsv1.SortByOldest:
+		less = func(a, b *appsv1.GetIdResponse) bool { return a.Time < b.Time }
+	case appsv1.SortByHot:
+		less = func(a, b *appsv1.GetIdResponse) bool { return Hotness(a, now) > Hotness(b, now) }
+	default:
+		less = func(a, b *appsv1.GetIdResponse) bool { return false }
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if less(results[i].Item, results[j].Item) {
+			return true
+		}
+		if less(results[j].Item, results[i].Item) {
+			return false
+		}
+		return results[i].Rank < results[j].Rank
+	})
+}
+
+// NeedsAll tells if all the items which match the filter
+// have to be fetched to find the first ones by the order
+func NeedsAll(order appsv1.SortOrder) bool {
+	return order != "" && order != appsv1.SortByRank
+}
@@ -0,0 +1,63 @@
+package sorting
+
+import (
+	"testing"
+	"time"
+
+	appsv1 "github.com/vadasambar/hnews/api/v1"
+	"github.com/vadasambar/hnews/pkg/hnapi"
+)
+
+func TestSort(t *testing.T) {
+	now := time.Unix(1650000000, 0)
+	ago := func(d time.Duration) int { return int(now.Add(-d).Unix()) }
+	items := []*appsv1.GetIdResponse{
+		{ID: 1, Score: 100, Descendants: 10, Time: ago(10 * time.Hour)},
+		{ID: 2, Score: 300, Descendants: 10, Time: ago(48 * time.Hour)},
+		{ID: 3, Score: 30, Descendants: 50, Time: ago(time.Hour)},
+		{ID: 4, Score: 100, Descendants: 5, Time: ago(20 * time.Hour)},
+	}
+
+	tests := []struct {
+		order appsv1.SortOrder
+		want  []int
+	}{
+		{"", []int{1, 2, 3, 4}},
+		{appsv1.SortByRank, []int{1, 2, 3, 4}},
+		{appsv1.SortByScore, []int{2, 1, 4, 3}},
+		{appsv1.SortByDescendants, []int{3, 1, 2, 4}},
+		{appsv1.SortByNewest, []int{3, 1, 4, 2}},
+		{appsv1.SortByOldest, []int{2, 4, 1, 3}},
+		{appsv1.SortByHot, []int{3, 1, 4, 2}},
+	}
+	for _, test := range tests {
+		results := []hnapi.Result{}
+		for rank, item := range items {
+			results = append(results, hnapi.Result{Rank: rank, Item: item})
+		}
+		Sort(results, test.order, now)
+		for i, result := range results {
+			if result.Item.ID != test.want[i] {
+				t.Errorf("Sort by %q = %v; want ids %v", test.order, ids(results), test.want)
+				break
+			}
+		}
+	}
+}
+
+func TestHotness(t *testing.T) {
+	now := time.Unix(1650000000, 0)
+	item := &appsv1.GetIdResponse{Score: 101, Time: int(now.Add(-2 * time.Hour).Unix())}
+	// 100 / 4^1.8
+	if got, want := Hotness(item, now), 8.2469; got < want-0.001 || got > want+0.001 {
+		t.Errorf("Hotness() = %f; want %f", got, want)
+	}
+}
+
+func ids(results []hnapi.Result) []int {
+	ids := []int{}
+	for _, result := range results {
+		ids = append(ids, result.Item.ID)
+	}
+	return ids
+}
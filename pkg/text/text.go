@@ -0,0 +1,81 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package text matches the text of Hacker News items (e.g., comments)
+// against the text filter of an HNews
+package text
+
+import (
+	"errors"
+	"unicode/utf8"
+
+	appsv1 "github.com/vadasambar/hnews/api/v1"
+	"github.com/vadasambar/hnews/pkg/htmltext"
+	"github.com/vadasambar/hnews/pkg/title"
+)
+
+// ErrLengths is returned when the text can't be both long enough and short enough
+var ErrLengths = errors.New("maxLength: must be greater than or equal to minLength")
+
+// Matcher matches texts against a text filter
+type Matcher struct {
+	keywords  *title.Matcher
+	minLength int
+	maxLength int
+}
+
+// Compile compiles the keywords and regular expressions of the filter.
+// They work like the ones of the title filter.
+func Compile(filter *appsv1.TextFilter) (*Matcher, error) {
+	if filter == nil {
+		keywords, _ := title.Compile(nil)
+		return &Matcher{keywords: keywords}, nil
+	}
+	if filter.MaxLength > 0 && filter.MaxLength < filter.MinLength {
+		return nil, ErrLengths
+	}
+
+	keywords, err := title.Compile(TitleFilter(filter))
+	if err != nil {
+		return nil, err
+	}
+	return &Matcher{
+		keywords:  keywords,
+		minLength: filter.MinLength,
+		maxLength: filter.MaxLength,
+	}, nil
+}
+
+// TitleFilter returns the title filter with the keywords and regular expressions of filter
+func TitleFilter(filter *appsv1.TextFilter) *appsv1.TitleFilter {
+	return &appsv1.TitleFilter{
+		Include:      filter.Include,
+		Exclude:      filter.Exclude,
+		IncludeRegex: filter.IncludeRegex,
+		ExcludeRegex: filter.ExcludeRegex,
+	}
+}
+
+// Matches tells if the HTML text of an item is as long as the filter wants
+// and matches its keywords and regular expressions, once the HTML is removed
+func (m *Matcher) Matches(html string) bool {
+	text := htmltext.Convert(html)
+	length := utf8.RuneCountInString(text)
+	if length < m.minLength || (m.maxLength > 0 && length > m.maxLength) {
+		return false
+	}
+	return m.keywords.Matches(text)
+}
@@ -0,0 +1,51 @@
+package text
+
+import (
+	"testing"
+
+	appsv1 "github.com/vadasambar/hnews/api/v1"
+)
+
+func TestMatches(t *testing.T) {
+	tests := []struct {
+		filter *appsv1.TextFilter
+		html   string
+		want   bool
+	}{
+		{nil, "", true},
+		{nil, "Anything goes", true},
+		{&appsv1.TextFilter{Include: []string{"go", "rust"}}, "I rewrote it in <i>Go</i>.", true},
+		{&appsv1.TextFilter{Include: []string{"go", "rust"}}, "Good point", false},
+		{&appsv1.TextFilter{Include: []string{"i"}}, "<i>Nope</i>", false},
+		{&appsv1.TextFilter{Exclude: []string{"crypto"}}, "Crypto &amp; friends", false},
+		{&appsv1.TextFilter{IncludeRegex: []string{`https://github\.com/`}}, `See <a href="https://github.com/vadasambar/hnews">github.com/vadasambar/hnews</a>`, true},
+		{&appsv1.TextFilter{MinLength: 10}, "Nice", false},
+		{&appsv1.TextFilter{MinLength: 10}, "Nice &amp; tidy", true},
+		{&appsv1.TextFilter{MinLength: 4, MaxLength: 4}, "Neat", true},
+		{&appsv1.TextFilter{MaxLength: 4}, "Café", true},
+		{&appsv1.TextFilter{MaxLength: 4}, "Cafés", false},
+		{&appsv1.TextFilter{MinLength: 1}, "", false},
+	}
+	for _, test := range tests {
+		m, err := Compile(test.filter)
+		if err != nil {
+			t.Errorf("Compile(%+v) returned error %v", test.filter, err)
+			continue
+		}
+		if got := m.Matches(test.html); got != test.want {
+			t.Errorf("Matches(%q) with %+v = %v; want %v", test.html, test.filter, got, test.want)
+		}
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	for _, filter := range []*appsv1.TextFilter{
+		{Include: []string{"go", " "}},
+		{ExcludeRegex: []string{"(unclosed"}},
+		{MinLength: 10, MaxLength: 5},
+	} {
+		if _, err := Compile(filter); err == nil {
+			t.Errorf("Compile(%+v) returned no error", filter)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package title matches the titles of Hacker News items
+// against the title filter of an HNews
+package title
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	appsv1 "github.com/vadasambar/hnews/api/v1"
+)
+
+// ErrEmptyKeyword is returned for a keyword which is only whitespace
+var ErrEmptyKeyword = errors.New("keyword can't be empty")
+
+// nonWord is anything which can't be a part of a word, so that keywords
+// match whole words even if they start or end with e.g., "+" as in "C++"
+const nonWord = `[^\pL\pN_]`
+
+// Matcher matches titles against a title filter
+type Matcher struct {
+	include []*regexp.Regexp
+	exclude []*regexp.Regexp
+}
+
+// Compile compiles the keywords and regular expressions of the filter
+func Compile(filter *appsv1.TitleFilter) (*Matcher, error) {
+	m := &Matcher{}
+	if filter == nil {
+		return m, nil
+	}
+
+	for _, keywords := range []struct {
+		name     string
+		keywords []string
+		into     *[]*regexp.Regexp
+	}{
+		{"include", filter.Include, &m.include},
+		{"exclude", filter.Exclude, &m.exclude},
+	} {
+		for i, keyword := range keywords.keywords {
+			re, err := Keyword(keyword)
+			if err != nil {
+				return nil, fmt.Errorf("%s[%d]: %w", keywords.name, i, err)
+			}
+			*keywords.into = append(*keywords.into, re)
+		}
+	}
+	for _, exprs := range []struct {
+		name  string
+		exprs []string
+		into  *[]*regexp.Regexp
+	}{
+		{"includeRegex", filter.IncludeRegex, &m.include},
+		{"excludeRegex", filter.ExcludeRegex, &m.exclude},
+	} {
+		for i, expr := range exprs.exprs {
+			re, err := regexp.Compile(expr)
+			if err != nil {
+				return nil, fmt.Errorf("%s[%d]: %w", exprs.name, i, err)
+			}
+			*exprs.into = append(*exprs.into, re)
+		}
+	}
+	return m, nil
+}
+
+// Keyword returns the regular expression which matches the keyword
+// as whole words, case insensitively and with any whitespace between them
+func Keyword(keyword string) (*regexp.Regexp, error) {
+	words := strings.Fields(keyword)
+	if len(words) == 0 {
+		return nil, ErrEmptyKeyword
+	}
+	for i, word := range words {
+		words[i] = regexp.QuoteMeta(word)
+	}
+	return regexp.Compile(`(?i)(?:^|` + nonWord + `)` + strings.Join(words, `\s+`) + `(?:$|` + nonWord + `)`)
+}
+
+// Matches tells if the title matches at least one of the included keywords
+// and regular expressions (if there are any) and none of the excluded ones
+func (m *Matcher) Matches(title string) bool {
+	for _, re := range m.exclude {
+		if re.MatchString(title) {
+			return false
+		}
+	}
+	if len(m.include) == 0 {
+		return true
+	}
+	for _, re := range m.include {
+		if re.MatchString(title) {
+			return true
+		}
+	}
+	return false
+}
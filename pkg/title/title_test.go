@@ -0,0 +1,52 @@
+package title
+
+import (
+	"testing"
+
+	appsv1 "github.com/vadasambar/hnews/api/v1"
+)
+
+func TestMatches(t *testing.T) {
+	tests := []struct {
+		filter *appsv1.TitleFilter
+		title  string
+		want   bool
+	}{
+		{nil, "Anything goes", true},
+		{&appsv1.TitleFilter{Include: []string{"kubernetes", "go", "postgres"}}, "Go 1.18 is released", true},
+		{&appsv1.TitleFilter{Include: []string{"kubernetes", "go", "postgres"}}, "Google announces a new phone", false},
+		{&appsv1.TitleFilter{Include: []string{"kubernetes", "go", "postgres"}}, "Scaling PostgreSQL", false},
+		{&appsv1.TitleFilter{Include: []string{"kubernetes", "go", "postgres"}}, "Why we left Kubernetes.", true},
+		{&appsv1.TitleFilter{Include: []string{"C++"}}, "Modern C++ in 2022", true},
+		{&appsv1.TitleFilter{Include: []string{"C++"}}, "Modern C in 2022", false},
+		{&appsv1.TitleFilter{Include: []string{"machine learning"}}, "Machine  Learning on a budget", true},
+		{&appsv1.TitleFilter{Include: []string{"go"}, Exclude: []string{"hiring"}}, "Go team is hiring", false},
+		{&appsv1.TitleFilter{Exclude: []string{"crypto"}}, "Cryptography 101", true},
+		{&appsv1.TitleFilter{Exclude: []string{"crypto"}}, "Crypto winter", false},
+		{&appsv1.TitleFilter{IncludeRegex: []string{`(?i)^show hn:`}}, "Show HN: A Kubernetes operator", true},
+		{&appsv1.TitleFilter{IncludeRegex: []string{`(?i)^show hn:`}, Include: []string{"rust"}}, "Rust 1.60", true},
+		{&appsv1.TitleFilter{IncludeRegex: []string{`(?i)^show hn:`}, ExcludeRegex: []string{`\bv[0-9]+\.[0-9]+\b`}}, "Show HN: hnews v1.2", false},
+	}
+	for _, test := range tests {
+		m, err := Compile(test.filter)
+		if err != nil {
+			t.Errorf("Compile(%+v) returned error %v", test.filter, err)
+			continue
+		}
+		if got := m.Matches(test.title); got != test.want {
+			t.Errorf("Matches(%q) with %+v = %v; want %v", test.title, test.filter, got, test.want)
+		}
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	for _, filter := range []*appsv1.TitleFilter{
+		{Include: []string{"go", " "}},
+		{ExcludeRegex: []string{"(unclosed"}},
+		{IncludeRegex: []string{`\p{Nope}`}},
+	} {
+		if _, err := Compile(filter); err == nil {
+			t.Errorf("Compile(%+v) returned no error", filter)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package velocity tracks how fast the Hacker News items
+// of an HNews are getting points and comments across syncs
+package velocity
+
+import (
+	"math"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	appsv1 "github.com/vadasambar/hnews/api/v1"
+)
+
+const (
+	// Window is the age after which the observation the velocity
+	// is measured from is moved forward to the previous sync
+	Window = time.Hour
+	// minElapsed is the shortest time a velocity is measured over
+	// so that a few points right after an observation don't look like a lot
+	minElapsed = time.Minute
+)
+
+// Tracker computes the velocity of items from the links of the previous sync
+type Tracker struct {
+	previous map[int]*appsv1.Link
+	// syncedAt is when the previous links were synced
+	syncedAt time.Time
+	now      time.Time
+}
+
+// NewTracker returns a Tracker for a sync at now.
+// previous are the links of the sync at syncedAt before it.
+func NewTracker(previous []appsv1.Link, syncedAt, now time.Time) *Tracker {
+	t := &Tracker{previous: map[int]*appsv1.Link{}, syncedAt: syncedAt, now: now}
+	for i := range previous {
+		t.previous[previous[i].ID] = &previous[i]
+	}
+	return t
+}
+
+// Track sets FirstSeenAt, PeakScore and Velocity of the link for the item.
+// It is safe to call concurrently.
+func (t *Tracker) Track(link *appsv1.Link, item *appsv1.GetIdResponse) {
+	now := metav1.NewTime(t.now)
+	link.FirstSeenAt = &now
+	link.PeakScore = item.Score
+	// the item had no points and no comments when it was posted
+	since := appsv1.Velocity{Since: metav1.NewTime(time.Unix(int64(item.Time), 0))}
+
+	if prev, ok := t.previous[item.ID]; ok {
+		if prev.FirstSeenAt != nil {
+			link.FirstSeenAt = prev.FirstSeenAt.DeepCopy()
+		}
+		if prev.PeakScore > link.PeakScore {
+			link.PeakScore = prev.PeakScore
+		}
+		if prev.Velocity != nil {
+			since = *prev.Velocity
+		}
+		if t.now.Sub(since.Since.Time) >= Window && t.now.Sub(t.syncedAt) >= minElapsed {
+			since = appsv1.Velocity{
+				Since:            metav1.NewTime(t.syncedAt),
+				SinceScore:       prev.Score,
+				SinceDescendents: prev.Descendents,
+			}
+		}
+	}
+
+	elapsed := t.now.Sub(since.Since.Time)
+	if elapsed < minElapsed {
+		elapsed = minElapsed
+	}
+	link.Velocity = &appsv1.Velocity{
+		Points:           perHour(item.Score-since.SinceScore, elapsed),
+		Comments:         perHour(item.Descendants-since.SinceDescendents, elapsed),
+		Since:            since.Since,
+		SinceScore:       since.SinceScore,
+		SinceDescendents: since.SinceDescendents,
+	}
+}
+
+func perHour(n int, elapsed time.Duration) int {
+	return int(math.Round(float64(n) / elapsed.Hours()))
+}
@@ -0,0 +1,52 @@
+package velocity
+
+import (
+	"testing"
+	"time"
+
+	appsv1 "github.com/vadasambar/hnews/api/v1"
+)
+
+func TestTrack(t *testing.T) {
+	posted := time.Unix(1650000000, 0)
+	item := &appsv1.GetIdResponse{ID: 1, Time: int(posted.Unix()), Score: 100, Descendants: 20}
+
+	// first seen two hours after it was posted
+	firstSync := posted.Add(2 * time.Hour)
+	var first appsv1.Link
+	NewTracker(nil, time.Time{}, firstSync).Track(&first, item)
+	if !first.FirstSeenAt.Time.Equal(firstSync) || first.PeakScore != 100 {
+		t.Errorf("got firstSeenAt %s and peakScore %d; want %s and 100", first.FirstSeenAt, first.PeakScore, firstSync)
+	}
+	if v := first.Velocity; v.Points != 50 || v.Comments != 10 || !v.Since.Time.Equal(posted) {
+		t.Errorf("got velocity %+v; want 50 points and 10 comments per hour since it was posted", v)
+	}
+	first.ID, first.Score, first.Descendents = 1, 100, 20
+
+	// the observation from when it was posted is over an hour old,
+	// so it's measured from the first sync
+	secondSync := firstSync.Add(30 * time.Minute)
+	item.Score, item.Descendants = 400, 30
+	var second appsv1.Link
+	NewTracker([]appsv1.Link{first}, firstSync, secondSync).Track(&second, item)
+	if !second.FirstSeenAt.Time.Equal(firstSync) || second.PeakScore != 400 {
+		t.Errorf("got firstSeenAt %s and peakScore %d; want %s and 400", second.FirstSeenAt, second.PeakScore, firstSync)
+	}
+	if v := second.Velocity; v.Points != 600 || v.Comments != 20 || !v.Since.Time.Equal(firstSync) {
+		t.Errorf("got velocity %+v; want 600 points and 20 comments per hour since the first sync", v)
+	}
+	second.ID, second.Score, second.Descendents = 1, 400, 30
+
+	// the observation from the first sync is kept for an hour
+	// and the peak score is kept when the score goes down
+	thirdSync := secondSync.Add(10 * time.Minute)
+	item.Score, item.Descendants = 380, 40
+	var third appsv1.Link
+	NewTracker([]appsv1.Link{second}, secondSync, thirdSync).Track(&third, item)
+	if third.PeakScore != 400 {
+		t.Errorf("got peakScore %d; want 400", third.PeakScore)
+	}
+	if v := third.Velocity; v.Points != 420 || v.Comments != 30 || !v.Since.Time.Equal(firstSync) {
+		t.Errorf("got velocity %+v; want 420 points and 30 comments per hour since the first sync", v)
+	}
+}
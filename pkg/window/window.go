@@ -0,0 +1,100 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package window finds the range of time Hacker News items
+// have to be posted in for the filter of an HNews
+package window
+
+import (
+	"fmt"
+	"time"
+
+	appsv1 "github.com/vadasambar/hnews/api/v1"
+)
+
+// Window is a range of time. A zero bound means the range is open on that side.
+type Window struct {
+	// since is inclusive
+	since time.Time
+	// until is exclusive
+	until time.Time
+}
+
+// FieldError is returned when a field of the filter is invalid
+type FieldError struct {
+	// Field is the name of the field in the filter e.g., maxAge
+	Field string
+	// Value is the value of the field
+	Value interface{}
+	Msg   string
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Msg)
+}
+
+// Validate returns a *FieldError if maxAge, minAge, since and until
+// of the filter can't be honored no matter when they are evaluated
+func Validate(filter *appsv1.Filter) error {
+	if filter.MaxAge != nil && filter.MaxAge.Duration <= 0 {
+		return &FieldError{Field: "maxAge", Value: filter.MaxAge.Duration.String(), Msg: "must be greater than 0"}
+	}
+	if filter.MinAge != nil && filter.MinAge.Duration < 0 {
+		return &FieldError{Field: "minAge", Value: filter.MinAge.Duration.String(), Msg: "must be greater than or equal to 0"}
+	}
+	if filter.MaxAge != nil && filter.MinAge != nil && filter.MinAge.Duration >= filter.MaxAge.Duration {
+		return &FieldError{Field: "minAge", Value: filter.MinAge.Duration.String(), Msg: "must be less than maxAge"}
+	}
+	if filter.Since != nil && filter.Until != nil && !filter.Until.After(filter.Since.Time) {
+		return &FieldError{Field: "until", Value: filter.Until, Msg: "must be after since"}
+	}
+	return nil
+}
+
+// New returns the window of the filter at now. maxAge and since
+// (and minAge and until) both bound the window when they are both set.
+func New(filter *appsv1.Filter, now time.Time) (Window, error) {
+	if err := Validate(filter); err != nil {
+		return Window{}, err
+	}
+
+	w := Window{}
+	if filter.Since != nil {
+		w.since = filter.Since.Time
+	}
+	if filter.MaxAge != nil {
+		if since := now.Add(-filter.MaxAge.Duration); since.After(w.since) {
+			w.since = since
+		}
+	}
+	if filter.Until != nil {
+		w.until = filter.Until.Time
+	}
+	if filter.MinAge != nil && filter.MinAge.Duration > 0 {
+		if until := now.Add(-filter.MinAge.Duration); w.until.IsZero() || until.Before(w.until) {
+			w.until = until
+		}
+	}
+	return w, nil
+}
+
+// Contains tells if t is in the window
+func (w Window) Contains(t time.Time) bool {
+	if !w.since.IsZero() && t.Before(w.since) {
+		return false
+	}
+	return w.until.IsZero() || t.Before(w.until)
+}
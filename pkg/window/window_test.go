@@ -0,0 +1,78 @@
+package window
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	appsv1 "github.com/vadasambar/hnews/api/v1"
+)
+
+func TestContains(t *testing.T) {
+	now := time.Date(2022, 5, 26, 12, 0, 0, 0, time.UTC)
+	hours := func(h int) *metav1.Duration { return &metav1.Duration{Duration: time.Duration(h) * time.Hour} }
+	at := func(hour int) *metav1.Time {
+		t := metav1.NewTime(time.Date(2022, 5, 26, hour, 0, 0, 0, time.UTC))
+		return &t
+	}
+
+	tests := []struct {
+		filter appsv1.Filter
+		posted time.Time
+		want   bool
+	}{
+		{appsv1.Filter{}, now.AddDate(-10, 0, 0), true},
+		{appsv1.Filter{MaxAge: hours(12)}, now.Add(-11 * time.Hour), true},
+		{appsv1.Filter{MaxAge: hours(12)}, now.Add(-13 * time.Hour), false},
+		{appsv1.Filter{MinAge: hours(1)}, now.Add(-30 * time.Minute), false},
+		{appsv1.Filter{MinAge: hours(1)}, now.Add(-2 * time.Hour), true},
+		{appsv1.Filter{MinAge: hours(0)}, now, true},
+		{appsv1.Filter{Since: at(8), Until: at(18)}, at(8).Time, true},
+		{appsv1.Filter{Since: at(8), Until: at(18)}, at(18).Time, false},
+		{appsv1.Filter{Since: at(8), Until: at(18)}, now.Add(-5 * time.Hour), false},
+		// maxAge is tighter than since and until is tighter than minAge
+		{appsv1.Filter{Since: at(0), MaxAge: hours(2), Until: at(11), MinAge: hours(0)}, now.Add(-90 * time.Minute), true},
+		{appsv1.Filter{Since: at(0), MaxAge: hours(2), Until: at(11), MinAge: hours(0)}, now.Add(-3 * time.Hour), false},
+		{appsv1.Filter{Since: at(0), MaxAge: hours(2), Until: at(11), MinAge: hours(0)}, now.Add(-30 * time.Minute), false},
+	}
+	for _, test := range tests {
+		w, err := New(&test.filter, now)
+		if err != nil {
+			t.Errorf("New(%+v) returned error %v", test.filter, err)
+			continue
+		}
+		if got := w.Contains(test.posted); got != test.want {
+			t.Errorf("Contains(%s) with %+v = %v; want %v", test.posted, test.filter, got, test.want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	hours := func(h int) *metav1.Duration { return &metav1.Duration{Duration: time.Duration(h) * time.Hour} }
+	since := metav1.NewTime(time.Date(2022, 5, 26, 8, 0, 0, 0, time.UTC))
+	until := metav1.NewTime(time.Date(2022, 5, 26, 7, 0, 0, 0, time.UTC))
+
+	tests := []struct {
+		filter appsv1.Filter
+		want   string
+		field  string
+	}{
+		{appsv1.Filter{MaxAge: hours(0)}, "maxAge:", "maxAge"},
+		{appsv1.Filter{MinAge: hours(-1)}, "minAge:", "minAge"},
+		{appsv1.Filter{MaxAge: hours(1), MinAge: hours(2)}, "minAge: must be less than maxAge", "minAge"},
+		{appsv1.Filter{Since: &since, Until: &until}, "until:", "until"},
+	}
+	for _, test := range tests {
+		err := Validate(&test.filter)
+		if err == nil || !strings.HasPrefix(err.Error(), test.want) {
+			t.Errorf("Validate(%+v) returned error %v; want %q...", test.filter, err, test.want)
+		}
+		var fieldErr *FieldError
+		if !errors.As(err, &fieldErr) || fieldErr.Field != test.field {
+			t.Errorf("Validate(%+v) returned error %#v; want a *FieldError for %s", test.filter, err, test.field)
+		}
+	}
+}
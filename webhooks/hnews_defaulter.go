@@ -0,0 +1,56 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	appsv1 "github.com/vadasambar/hnews/api/v1"
+)
+
+//+kubebuilder:webhook:path=/mutate-apps-vadasambar-com-v1-hnews,mutating=true,failurePolicy=fail,sideEffects=None,groups=apps.vadasambar.com,resources=hnews,verbs=create;update,versions=v1,name=mhnews.kb.io,admissionReviewVersions=v1
+
+// HNewsDefaulter sets the fields of the spec of HNews which aren't set
+// when they are created or updated, so that the controller never has to
+// write to the spec (which GitOps tools would see as drift)
+type HNewsDefaulter struct {
+	// Defaults are the values the fields are set to.
+	// appsv1.BuiltinDefaults are used for the ones which aren't set.
+	Defaults appsv1.Defaults
+}
+
+// SetupWebhookWithManager registers the webhook with the manager.
+func (d *HNewsDefaulter) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&appsv1.HNews{}).
+		WithDefaulter(d).
+		Complete()
+}
+
+// Default implements admission.CustomDefaulter
+func (d *HNewsDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	hn, ok := obj.(*appsv1.HNews)
+	if !ok {
+		return fmt.Errorf("expected an HNews but got %T", obj)
+	}
+	d.Defaults.Apply(&hn.Spec)
+	return nil
+}
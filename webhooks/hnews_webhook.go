@@ -0,0 +1,339 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	appsv1 "github.com/vadasambar/hnews/api/v1"
+	"github.com/vadasambar/hnews/pkg/author"
+	"github.com/vadasambar/hnews/pkg/comparison"
+	"github.com/vadasambar/hnews/pkg/domain"
+	"github.com/vadasambar/hnews/pkg/expression"
+	"github.com/vadasambar/hnews/pkg/text"
+	"github.com/vadasambar/hnews/pkg/title"
+	"github.com/vadasambar/hnews/pkg/window"
+)
+
+//+kubebuilder:webhook:path=/validate-apps-vadasambar-com-v1-hnews,mutating=false,failurePolicy=fail,sideEffects=None,groups=apps.vadasambar.com,resources=hnews,verbs=create;update,versions=v1,name=vhnews.kb.io,admissionReviewVersions=v1
+//+kubebuilder:rbac:groups="",resources=namespaces,verbs=get
+
+// HNewsValidator rejects HNews with a spec which can't be honored
+// or which isn't allowed by the policy of their namespace
+type HNewsValidator struct {
+	// Reader is used to get the namespace of an HNews for its policy
+	client.Reader
+}
+
+// SetupWebhookWithManager registers the webhook with the manager.
+func (v *HNewsValidator) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	if v.Reader == nil {
+		// the namespaces aren't watched so they aren't in the cache
+		v.Reader = mgr.GetAPIReader()
+	}
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&appsv1.HNews{}).
+		WithValidator(v).
+		Complete()
+}
+
+// ValidateCreate implements admission.CustomValidator
+func (v *HNewsValidator) ValidateCreate(ctx context.Context, obj runtime.Object) error {
+	hn, ok := obj.(*appsv1.HNews)
+	if !ok {
+		return fmt.Errorf("expected an HNews but got %T", obj)
+	}
+	return v.validate(ctx, hn)
+}
+
+// ValidateUpdate implements admission.CustomValidator
+func (v *HNewsValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) error {
+	oldHN, ok := oldObj.(*appsv1.HNews)
+	if !ok {
+		return fmt.Errorf("expected an HNews but got %T", oldObj)
+	}
+	hn, ok := newObj.(*appsv1.HNews)
+	if !ok {
+		return fmt.Errorf("expected an HNews but got %T", newObj)
+	}
+	// e.g., labels can be changed even if the policy of
+	// the namespace changed since the HNews was created
+	if equality.Semantic.DeepEqual(oldHN.Spec, hn.Spec) {
+		return nil
+	}
+	return v.validate(ctx, hn)
+}
+
+// ValidateDelete implements admission.CustomValidator
+func (v *HNewsValidator) ValidateDelete(ctx context.Context, obj runtime.Object) error {
+	return nil
+}
+
+func (v *HNewsValidator) validate(ctx context.Context, hn *appsv1.HNews) error {
+	allErrs := ValidateSpec(&hn.Spec, field.NewPath("spec"))
+
+	policyErrs, err := v.validatePolicy(ctx, hn)
+	if err != nil {
+		log.Log.Error(err, "unable to get the policy of the namespace", "name", hn.Name, "namespace", hn.Namespace)
+		return apierrors.NewInternalError(err)
+	}
+	allErrs = append(allErrs, policyErrs...)
+
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(appsv1.GroupVersion.WithKind("HNews").GroupKind(), hn.Name, allErrs)
+}
+
+// ValidateSpec returns the reasons the spec can't be honored.
+// Fields which aren't set are fine as they are defaulted.
+func ValidateSpec(spec *appsv1.HNewsSpec, specPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	filter := &spec.Filter
+	filterPath := specPath.Child("filter")
+
+	if filter.Limit < 0 {
+		allErrs = append(allErrs, field.Invalid(filterPath.Child("limit"), filter.Limit, "must be greater than or equal to 0"))
+	}
+	if filter.Score != "" {
+		if _, err := comparison.Parse(string(filter.Score)); err != nil {
+			allErrs = append(allErrs, field.Invalid(filterPath.Child("score"), filter.Score, err.Error()))
+		}
+	}
+	if filter.Descendants != "" {
+		if _, err := comparison.Parse(string(filter.Descendants)); err != nil {
+			allErrs = append(allErrs, field.Invalid(filterPath.Child("descendents"), filter.Descendants, err.Error()))
+		}
+	}
+	if filter.Velocity != "" {
+		if _, err := comparison.Parse(string(filter.Velocity)); err != nil {
+			allErrs = append(allErrs, field.Invalid(filterPath.Child("velocity"), filter.Velocity, err.Error()))
+		}
+	}
+	if filter.Title != nil {
+		allErrs = append(allErrs, validateTitleFilter(filter.Title, filterPath.Child("title"))...)
+	}
+	if filter.Domains != nil {
+		allErrs = append(allErrs, validateDomainFilter(filter.Domains, filterPath.Child("domains"))...)
+	}
+	if filter.Author != nil {
+		allErrs = append(allErrs, validateAuthorFilter(filter.Author, filterPath.Child("author"))...)
+	}
+	if filter.Text != nil {
+		allErrs = append(allErrs, validateTextFilter(filter.Text, filterPath.Child("text"))...)
+	}
+	allErrs = append(allErrs, validateTimeWindow(filter, filterPath)...)
+	if filter.Expression != "" {
+		if _, err := expression.Compile(filter.Expression); err != nil {
+			allErrs = append(allErrs, field.Invalid(filterPath.Child("expression"), filter.Expression, err.Error()))
+		}
+	}
+
+	if spec.Threads != nil && spec.Threads.Timeout != nil && spec.Threads.Timeout.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("threads", "timeout"), spec.Threads.Timeout.Duration.String(), "must be greater than 0"))
+	}
+	if spec.SyncInterval != nil && spec.SyncInterval.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("syncInterval"), spec.SyncInterval.Duration.String(), "must be greater than 0"))
+	}
+
+	if filter.Type == "" {
+		return allErrs
+	}
+	itemType := appsv1.Type(filter.Type)
+	if itemType == appsv1.Comment {
+		allErrs = append(allErrs, validateCommentFilter(filter, filterPath)...)
+		if spec.Sort != "" && spec.Sort != appsv1.SortByRank {
+			allErrs = append(allErrs, field.Forbidden(specPath.Child("sort"), "comments are kept in the order of their threads"))
+		}
+	}
+	if spec.Feed == "" && len(spec.Feeds) == 0 && appsv1.DefaultFeedFor(itemType) == "" {
+		allErrs = append(allErrs, field.Invalid(filterPath.Child("type"), filter.Type, "items of this type are not in any feed"))
+	}
+	forEachFeed(spec, specPath, func(feed appsv1.Feed, feedPath *field.Path) {
+		if !feed.CanContain(itemType) {
+			allErrs = append(allErrs, field.Invalid(feedPath, feed, fmt.Sprintf("feed can't contain items of type %q", itemType)))
+		}
+	})
+	return allErrs
+}
+
+// validateTitleFilter returns the keywords and regular expressions which are invalid
+func validateTitleFilter(filter *appsv1.TitleFilter, titlePath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	validateKeywords := func(name string, keywords []string) {
+		for i, keyword := range keywords {
+			if _, err := title.Keyword(keyword); err != nil {
+				allErrs = append(allErrs, field.Invalid(titlePath.Child(name).Index(i), keyword, err.Error()))
+			}
+		}
+	}
+	validateRegexes := func(name string, exprs []string) {
+		for i, expr := range exprs {
+			if _, err := regexp.Compile(expr); err != nil {
+				allErrs = append(allErrs, field.Invalid(titlePath.Child(name).Index(i), expr, err.Error()))
+			}
+		}
+	}
+	validateKeywords("include", filter.Include)
+	validateKeywords("exclude", filter.Exclude)
+	validateRegexes("includeRegex", filter.IncludeRegex)
+	validateRegexes("excludeRegex", filter.ExcludeRegex)
+	return allErrs
+}
+
+// validateDomainFilter returns the domains which are invalid
+func validateDomainFilter(filter *appsv1.DomainFilter, domainsPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	validateDomains := func(name string, domains []string) {
+		for i, d := range domains {
+			if err := domain.Validate(d); err != nil {
+				allErrs = append(allErrs, field.Invalid(domainsPath.Child(name).Index(i), d, err.Error()))
+			}
+		}
+	}
+	validateDomains("include", filter.Include)
+	validateDomains("exclude", filter.Exclude)
+	if filter.SelfPosts == appsv1.OnlySelfPosts && len(filter.Include) > 0 {
+		allErrs = append(allErrs, field.Invalid(domainsPath.Child("include"), filter.Include, "self posts don't have a domain, so it can't be set along with selfPosts: only"))
+	}
+	return allErrs
+}
+
+// validateAuthorFilter returns the usernames, the karma and the account age which are invalid
+func validateAuthorFilter(filter *appsv1.AuthorFilter, authorPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	validateUsernames := func(name string, usernames []string) {
+		for i, username := range usernames {
+			if err := author.Validate(username); err != nil {
+				allErrs = append(allErrs, field.Invalid(authorPath.Child(name).Index(i), username, err.Error()))
+			}
+		}
+	}
+	validateUsernames("include", filter.Include)
+	validateUsernames("exclude", filter.Exclude)
+	if filter.Karma != "" {
+		if _, err := comparison.Parse(string(filter.Karma)); err != nil {
+			allErrs = append(allErrs, field.Invalid(authorPath.Child("karma"), filter.Karma, err.Error()))
+		}
+	}
+	if filter.MinAccountAge != nil && filter.MinAccountAge.Duration < 0 {
+		allErrs = append(allErrs, field.Invalid(authorPath.Child("minAccountAge"), filter.MinAccountAge.Duration.String(), "must be greater than or equal to 0"))
+	}
+	return allErrs
+}
+
+// validateTextFilter returns the keywords, regular expressions and lengths which are invalid
+func validateTextFilter(filter *appsv1.TextFilter, textPath *field.Path) field.ErrorList {
+	allErrs := validateTitleFilter(text.TitleFilter(filter), textPath)
+	if filter.MaxLength > 0 && filter.MaxLength < filter.MinLength {
+		allErrs = append(allErrs, field.Invalid(textPath.Child("maxLength"), filter.MaxLength, "must be greater than or equal to minLength"))
+	}
+	return allErrs
+}
+
+// validateCommentFilter returns the fields of the filter which can't apply to comments.
+// Score and descendents aren't in the list as they are always defaulted.
+func validateCommentFilter(filter *appsv1.Filter, filterPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if filter.Title != nil {
+		allErrs = append(allErrs, field.Forbidden(filterPath.Child("title"), "comments don't have a title, use text instead"))
+	}
+	if filter.Domains != nil {
+		allErrs = append(allErrs, field.Forbidden(filterPath.Child("domains"), "comments don't have a URL"))
+	}
+	if filter.Velocity != "" {
+		allErrs = append(allErrs, field.Forbidden(filterPath.Child("velocity"), "comments don't have a score"))
+	}
+	return allErrs
+}
+
+// validateTimeWindow returns the age or time in the filter which is invalid
+// or which leaves no time for the articles to be posted in
+func validateTimeWindow(filter *appsv1.Filter, filterPath *field.Path) field.ErrorList {
+	err := window.Validate(filter)
+	if err == nil {
+		return nil
+	}
+	var fieldErr *window.FieldError
+	if !errors.As(err, &fieldErr) {
+		return field.ErrorList{field.Invalid(filterPath, filter, err.Error())}
+	}
+	return field.ErrorList{field.Invalid(filterPath.Child(fieldErr.Field), fieldErr.Value, fieldErr.Msg)}
+}
+
+// validatePolicy returns the reasons the HNews isn't allowed by the policy of its namespace
+func (v *HNewsValidator) validatePolicy(ctx context.Context, hn *appsv1.HNews) (field.ErrorList, error) {
+	var ns corev1.Namespace
+	if err := v.Get(ctx, client.ObjectKey{Name: hn.Namespace}, &ns); err != nil {
+		return nil, err
+	}
+
+	allErrs := field.ErrorList{}
+	specPath := field.NewPath("spec")
+	if maxLimit, ok := ns.Annotations[appsv1.MaxLimitAnnotation]; ok {
+		max, err := strconv.Atoi(strings.TrimSpace(maxLimit))
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s annotation %q on namespace %s: %w", appsv1.MaxLimitAnnotation, maxLimit, ns.Name, err)
+		}
+		if hn.Spec.Filter.Limit > max {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("filter", "limit"), hn.Spec.Filter.Limit,
+				fmt.Sprintf("must be less than or equal to %d in namespace %s", max, ns.Name)))
+		}
+	}
+	if allowedFeeds, ok := ns.Annotations[appsv1.AllowedFeedsAnnotation]; ok {
+		allowed := map[appsv1.Feed]bool{}
+		for _, feed := range strings.Split(allowedFeeds, ",") {
+			allowed[appsv1.Feed(strings.TrimSpace(feed))] = true
+		}
+		selected := false
+		forEachFeed(&hn.Spec, specPath, func(feed appsv1.Feed, feedPath *field.Path) {
+			selected = true
+			if !allowed[feed] {
+				allErrs = append(allErrs, field.Forbidden(feedPath, fmt.Sprintf("feed %q isn't allowed in namespace %s (allowed: %s)", feed, ns.Name, allowedFeeds)))
+			}
+		})
+		if feed := appsv1.DefaultFeedFor(appsv1.Type(hn.Spec.Filter.Type)); !selected && feed != "" && !allowed[feed] {
+			allErrs = append(allErrs, field.Forbidden(specPath.Child("feed"),
+				fmt.Sprintf("the default feed %q for items of type %q isn't allowed in namespace %s (allowed: %s)", feed, hn.Spec.Filter.Type, ns.Name, allowedFeeds)))
+		}
+	}
+	return allErrs, nil
+}
+
+// forEachFeed calls fn with every feed selected in the spec and its path
+func forEachFeed(spec *appsv1.HNewsSpec, specPath *field.Path, fn func(feed appsv1.Feed, feedPath *field.Path)) {
+	if spec.Feed != "" {
+		fn(spec.Feed, specPath.Child("feed"))
+	}
+	for i, feed := range spec.Feeds {
+		fn(feed, specPath.Child("feeds").Index(i))
+	}
+}
@@ -0,0 +1,170 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	hnewsv1 "github.com/vadasambar/hnews/api/v1"
+)
+
+// causes returns the fields err says are invalid
+func causes(err error) []string {
+	var statusErr *apierrors.StatusError
+	if !errors.As(err, &statusErr) || statusErr.ErrStatus.Details == nil {
+		return nil
+	}
+	fields := []string{}
+	for _, cause := range statusErr.ErrStatus.Details.Causes {
+		fields = append(fields, cause.Field)
+	}
+	return fields
+}
+
+var _ = Describe("HNews webhook", func() {
+	Context("When creating HNews", func() {
+		It("It should fill in the defaults for an empty `spec`", func() {
+			ctx := context.Background()
+			hnews := &hnewsv1.HNews{
+				ObjectMeta: metav1.ObjectMeta{Name: "hnews-defaults", Namespace: "default"},
+			}
+			Expect(k8sClient.Create(ctx, hnews)).Should(Succeed())
+
+			filter := hnews.Spec.Filter
+			Expect(filter.Type).To(Equal(string(hnewsv1.BuiltinDefaults.Type)))
+			Expect(filter.Limit).To(Equal(hnewsv1.BuiltinDefaults.Limit))
+			Expect(filter.Score).To(Equal(hnewsv1.BuiltinDefaults.Score))
+			Expect(filter.Descendants).To(Equal(hnewsv1.BuiltinDefaults.Descendants))
+		})
+
+		It("It should accept a valid `spec`", func() {
+			ctx := context.Background()
+			hnews := &hnewsv1.HNews{
+				ObjectMeta: metav1.ObjectMeta{Name: "hnews-valid", Namespace: "default"},
+				Spec: hnewsv1.HNewsSpec{
+					Feeds: []hnewsv1.Feed{hnewsv1.TopFeed, hnewsv1.ShowFeed},
+					Filter: hnewsv1.Filter{
+						Type:        string(hnewsv1.Story),
+						Limit:       5,
+						Score:       ">=10,<1000",
+						Descendants: "between 0 and 100",
+						Expression:  `item.score > 100 || item.descendants > 300`,
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, hnews)).Should(Succeed())
+		})
+
+		It("It should reject an invalid `spec` with the path of every invalid field", func() {
+			ctx := context.Background()
+			hnews := &hnewsv1.HNews{
+				ObjectMeta: metav1.ObjectMeta{Name: "hnews-invalid", Namespace: "default"},
+				Spec: hnewsv1.HNewsSpec{
+					Feeds: []hnewsv1.Feed{hnewsv1.JobFeed, hnewsv1.ShowFeed},
+					Filter: hnewsv1.Filter{
+						Type:        string(hnewsv1.Job),
+						Limit:       5,
+						Score:       "abc",
+						Descendants: ">= -5",
+						Expression:  `item.points > 100`,
+						Title: &hnewsv1.TitleFilter{
+							Include:      []string{"go", " "},
+							IncludeRegex: []string{`(?i)^show hn:`, "(unclosed"},
+						},
+						Domains: &hnewsv1.DomainFilter{
+							Exclude: []string{"wsj.com", "https://ft.com"},
+						},
+						Author: &hnewsv1.AuthorFilter{
+							Exclude: []string{"spam bot"},
+							Karma:   "lots",
+						},
+						Text: &hnewsv1.TextFilter{
+							ExcludeRegex: []string{"(unclosed"},
+							MinLength:    100,
+							MaxLength:    10,
+						},
+						Velocity: ">fast",
+						MaxAge:   &metav1.Duration{Duration: time.Hour},
+						MinAge:   &metav1.Duration{Duration: 2 * time.Hour},
+					},
+					Threads: &hnewsv1.ThreadsSpec{Timeout: &metav1.Duration{Duration: -time.Second}},
+				},
+			}
+			err := k8sClient.Create(ctx, hnews)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue(), "got error %v", err)
+			Expect(causes(err)).To(ConsistOf("spec.filter.score", "spec.filter.expression", "spec.feeds[1]",
+				"spec.filter.title.include[1]", "spec.filter.title.includeRegex[1]", "spec.filter.domains.exclude[1]",
+				"spec.filter.author.exclude[0]", "spec.filter.author.karma", "spec.filter.minAge",
+				"spec.filter.velocity", "spec.filter.text.excludeRegex[0]", "spec.filter.text.maxLength",
+				"spec.threads.timeout"))
+		})
+
+		It("It should reject the fields of the filter which can't apply to comments", func() {
+			ctx := context.Background()
+			hnews := &hnewsv1.HNews{
+				ObjectMeta: metav1.ObjectMeta{Name: "hnews-comments-invalid", Namespace: "default"},
+				Spec: hnewsv1.HNewsSpec{
+					Feeds: []hnewsv1.Feed{hnewsv1.TopFeed, hnewsv1.JobFeed},
+					Filter: hnewsv1.Filter{
+						Type:     string(hnewsv1.Comment),
+						Limit:    5,
+						Title:    &hnewsv1.TitleFilter{Include: []string{"go"}},
+						Domains:  &hnewsv1.DomainFilter{Include: []string{"github.com"}},
+						Velocity: ">10",
+						Text:     &hnewsv1.TextFilter{Include: []string{"go"}},
+					},
+					Sort: hnewsv1.SortByScore,
+				},
+			}
+			err := k8sClient.Create(ctx, hnews)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue(), "got error %v", err)
+			Expect(causes(err)).To(ConsistOf("spec.filter.title", "spec.filter.domains", "spec.filter.velocity", "spec.feeds[1]", "spec.sort"))
+
+			By("By accepting it without them")
+			hnews.Spec.Feeds = []hnewsv1.Feed{hnewsv1.TopFeed}
+			hnews.Spec.Filter.Title, hnews.Spec.Filter.Domains, hnews.Spec.Filter.Velocity = nil, nil, ""
+			hnews.Spec.Sort = hnewsv1.SortByRank
+			Expect(k8sClient.Create(ctx, hnews)).Should(Succeed())
+		})
+
+		It("It should enforce the policy of the namespace", func() {
+			ctx := context.Background()
+			ns := &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "hnews-policy",
+					Annotations: map[string]string{
+						hnewsv1.MaxLimitAnnotation:     "3",
+						hnewsv1.AllowedFeedsAnnotation: "best,job",
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, ns)).Should(Succeed())
+
+			hnews := &hnewsv1.HNews{
+				ObjectMeta: metav1.ObjectMeta{Name: "hnews-policy", Namespace: "hnews-policy"},
+				Spec: hnewsv1.HNewsSpec{
+					Filter: hnewsv1.Filter{
+						Type:        string(hnewsv1.Story),
+						Limit:       5,
+						Score:       ">10",
+						Descendants: ">0",
+					},
+				},
+			}
+			err := k8sClient.Create(ctx, hnews)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue(), "got error %v", err)
+			Expect(causes(err)).To(ConsistOf("spec.filter.limit", "spec.feed"))
+
+			By("By accepting it once it's within the policy")
+			hnews.Spec.Feed = hnewsv1.BestFeed
+			hnews.Spec.Filter.Limit = 3
+			Expect(k8sClient.Create(ctx, hnews)).Should(Succeed())
+		})
+	})
+})